@@ -0,0 +1,108 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Accessor 提供了不通过反射访问模型各列的方法。
+//
+// 一般由 cmd/ormaccessor 根据模型的 struct tag 生成，
+// 实现了该接口的对象，在 Insert()、Update()、Delete() 和 Select() 等操作中，
+// 会直接通过这些方法获取各列的值，而不是通过反射查找字段。
+// 查询结果的导出可参考 fetch.ScanTargeter。
+//
+// 修改了模型的结构之后需要重新生成，NewModel() 发现 OrmColumns()
+// 或 OrmPK() 与模型中的列不一致时会返回错误。
+type Accessor interface {
+	// 返回所有列的名称。
+	OrmColumns() []string
+
+	// 返回所有列的值，顺序与 OrmColumns() 相同。
+	OrmValues() []interface{}
+
+	// 返回主键各列的值，顺序与 Model.PK 相同。
+	//
+	// Update() 和 Delete() 等操作在构建 where 条件时使用。
+	OrmPK() []interface{}
+}
+
+// 根据 Accessor.OrmColumns() 生成列名与其位置的对应关系，
+// rtype 未实现 Accessor 时返回 nil。
+func accessorIndex(m *Model, rtype reflect.Type) (map[string]int, error) {
+	a, ok := reflect.New(rtype).Interface().(Accessor)
+	if !ok {
+		return nil, nil
+	}
+
+	cols := a.OrmColumns()
+	if len(cols) != len(m.Cols) {
+		return nil, fmt.Errorf("%s 的 OrmColumns() 与模型中的列不一致，请重新生成", rtype.Name())
+	}
+
+	index := make(map[string]int, len(cols))
+	for i, name := range cols {
+		if _, found := m.Cols[name]; !found {
+			return nil, fmt.Errorf("%s 的 OrmColumns() 中的列 %s 不存在于模型中，请重新生成", rtype.Name(), name)
+		}
+		index[name] = i
+	}
+
+	if len(a.OrmPK()) != len(m.PK) {
+		return nil, fmt.Errorf("%s 的 OrmPK() 与模型中的主键不一致，请重新生成", rtype.Name())
+	}
+
+	return index, nil
+}
+
+// 获取对象中各列的值
+//
+// 对象实现了 Accessor 接口时，通过接口方法获取，否则通过反射获取。
+type columnValues struct {
+	m    *Model
+	v    interface{}
+	rval reflect.Value
+	vals []interface{} // Accessor.OrmValues() 的返回值
+}
+
+func newColumnValues(m *Model, v interface{}, rval reflect.Value) *columnValues {
+	cv := &columnValues{m: m, v: v, rval: rval}
+	if a, ok := v.(Accessor); ok && m.accessor != nil {
+		cv.vals = a.OrmValues()
+	}
+	return cv
+}
+
+// 返回列 col 的值
+func (cv *columnValues) get(col *Column) (interface{}, error) {
+	if cv.vals != nil {
+		return cv.vals[cv.m.accessor[col.Name]], nil
+	}
+
+	field := cv.rval.FieldByName(col.GoName)
+	if !field.IsValid() {
+		return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+	}
+	return field.Interface(), nil
+}
+
+// 返回主键各列的值
+func (cv *columnValues) pk() ([]interface{}, error) {
+	if a, ok := cv.v.(Accessor); ok && cv.m.accessor != nil {
+		return a.OrmPK(), nil
+	}
+
+	vals := make([]interface{}, 0, len(cv.m.PK))
+	for _, col := range cv.m.PK {
+		val, err := cv.get(col)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/ormtest"
+)
+
+// OrmColumns() 与结构体不一致的 Accessor
+type staleAccessor struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20)"`
+}
+
+func (m *staleAccessor) OrmColumns() []string {
+	return []string{"id", "title"}
+}
+
+func (m *staleAccessor) OrmValues() []interface{} {
+	return []interface{}{m.ID, m.Name}
+}
+
+func (m *staleAccessor) OrmPK() []interface{} {
+	return []interface{}{m.ID}
+}
+
+// OrmPK() 与模型中的主键不一致的 Accessor
+type stalePKAccessor struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20)"`
+}
+
+func (m *stalePKAccessor) OrmColumns() []string {
+	return []string{"id", "name"}
+}
+
+func (m *stalePKAccessor) OrmValues() []interface{} {
+	return []interface{}{m.ID, m.Name}
+}
+
+func (m *stalePKAccessor) OrmPK() []interface{} {
+	return []interface{}{m.ID, m.Name}
+}
+
+// OrmValues() 返回的值与字段不同，用于判断是否通过 Accessor 获取值。
+type upperAccessor struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20)"`
+}
+
+func (m *upperAccessor) Meta() string {
+	return "name(upper)"
+}
+
+func (m *upperAccessor) OrmColumns() []string {
+	return []string{"id", "name"}
+}
+
+func (m *upperAccessor) OrmValues() []interface{} {
+	return []interface{}{m.ID * 10, strings.ToUpper(m.Name)}
+}
+
+func (m *upperAccessor) OrmPK() []interface{} {
+	return []interface{}{m.ID + 100}
+}
+
+func TestAccessor_values(t *testing.T) {
+	a := assert.New(t)
+
+	db, rec := ormtest.New(dialect.Sqlite3(), "")
+	defer func() {
+		a.NotError(db.Close())
+	}()
+
+	_, err := db.Insert(&upperAccessor{Name: "abc"})
+	a.NotError(err)
+	a.Equal(rec.Statements()[0].Args, []interface{}{"ABC"})
+
+	rec.Reset()
+	_, err = db.Delete(&upperAccessor{ID: 1})
+	a.NotError(err)
+	a.Equal(rec.Statements()[0].Args, []interface{}{int64(101)}) // 主键通过 OrmPK() 获取
+
+	rec.Reset()
+	_, err = db.BulkLoad([]*upperAccessor{{Name: "x"}, {Name: "y"}})
+	a.NotError(err)
+	a.Equal(rec.Statements()[0].Args, []interface{}{"X", "Y"})
+}
+
+func TestAccessor(t *testing.T) {
+	a := assert.New(t)
+
+	m, err := orm.NewModel(&staleAccessor{})
+	a.Error(err).Nil(m)
+
+	m, err = orm.NewModel(&stalePKAccessor{})
+	a.Error(err).Nil(m)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Article{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+	a.NotError(db.Create(&modeltest.Article{}))
+
+	_, err = db.Insert(&modeltest.Article{Title: "t1", Content: "c1", Created: 1})
+	a.NotError(err)
+	a.NotError(db.MultInsert(
+		&modeltest.Article{Title: "t2", Content: "c2"},
+		&modeltest.Article{Title: "t3", Content: "c3"},
+	))
+
+	// 通过主键查找
+	art := &modeltest.Article{ID: 1}
+	a.NotError(db.Select(art))
+	a.Equal(art, &modeltest.Article{ID: 1, Title: "t1", Content: "c1", Created: 1})
+
+	// 通过唯一约束查找
+	art = &modeltest.Article{Title: "t2"}
+	a.NotError(db.Select(art))
+	a.Equal(art.ID, 2).Equal(art.Content, "c2")
+
+	_, err = db.Update(&modeltest.Article{ID: 2, Content: "c2-2"})
+	a.NotError(err)
+	art = &modeltest.Article{ID: 2}
+	a.NotError(db.Select(art))
+	a.Equal(art.Title, "t2").Equal(art.Content, "c2-2")
+
+	cnt, err := db.Count(&modeltest.Article{Created: 1})
+	a.NotError(err).Equal(cnt, 1)
+
+	_, err = db.Delete(&modeltest.Article{ID: 1})
+	a.NotError(err)
+	n, err := db.DeleteMany([]*modeltest.Article{{ID: 2}, {ID: 3}})
+	a.NotError(err).Equal(n, 2)
+	hasCount(db, a, "articles", 0)
+}
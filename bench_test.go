@@ -10,10 +10,95 @@ import (
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
 	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/ormtest"
 	"github.com/issue9/orm/sqlbuilder"
 )
 
+// 与 modeltest.Article 相同的结构，但是没有实现 orm.Accessor，
+// 用于比较反射与 ormaccessor 生成的代码之间的性能差异。
+type reflectArticle struct {
+	ID      int64  `orm:"name(id);ai"`
+	Title   string `orm:"name(title);len(200);unique(unique_title)"`
+	Content string `orm:"name(content);len(-1)"`
+	Created int64  `orm:"name(created)"`
+}
+
+func (m *reflectArticle) Meta() string {
+	return "name(articles)"
+}
+
+// 以下通过 ormtest 执行，不包含数据库本身的耗时。
+//
+// BenchmarkAccessor_Insert/reflect-4  	  447933	      2708 ns/op
+// BenchmarkAccessor_Insert/accessor-4 	  498214	      2400 ns/op
+func BenchmarkAccessor_Insert(b *testing.B) {
+	db, rec := ormtest.New(dialect.Sqlite3(), "")
+	defer db.Close()
+
+	bench := func(v interface{}) func(b *testing.B) {
+		return func(b *testing.B) {
+			a := assert.New(b)
+			for i := 0; i < b.N; i++ {
+				_, err := db.Insert(v)
+				a.NotError(err)
+				rec.Reset()
+			}
+		}
+	}
+
+	b.Run("reflect", bench(&reflectArticle{Title: "title", Content: "content", Created: 1}))
+	b.Run("accessor", bench(&modeltest.Article{Title: "title", Content: "content", Created: 1}))
+}
+
+// BenchmarkAccessor_Update/reflect-4  	  328958	      3168 ns/op
+// BenchmarkAccessor_Update/accessor-4 	  407324	      2882 ns/op
+func BenchmarkAccessor_Update(b *testing.B) {
+	db, rec := ormtest.New(dialect.Sqlite3(), "")
+	defer db.Close()
+
+	bench := func(v interface{}) func(b *testing.B) {
+		return func(b *testing.B) {
+			a := assert.New(b)
+			for i := 0; i < b.N; i++ {
+				_, err := db.Update(v)
+				a.NotError(err)
+				rec.Reset()
+			}
+		}
+	}
+
+	b.Run("reflect", bench(&reflectArticle{ID: 1, Title: "title", Created: 1}))
+	b.Run("accessor", bench(&modeltest.Article{ID: 1, Title: "title", Created: 1}))
+}
+
+// BenchmarkAccessor_Select/reflect-4  	  293581	      3995 ns/op
+// BenchmarkAccessor_Select/accessor-4 	  455493	      2541 ns/op
+func BenchmarkAccessor_Select(b *testing.B) {
+	db, rec := ormtest.New(dialect.Sqlite3(), "")
+	defer db.Close()
+
+	result := &ormtest.Result{
+		Columns: []string{"id", "title", "content", "created"},
+		Rows:    [][]interface{}{{int64(1), "title", "content", int64(1)}},
+	}
+
+	bench := func(v interface{}) func(b *testing.B) {
+		return func(b *testing.B) {
+			a := assert.New(b)
+			for i := 0; i < b.N; i++ {
+				rec.Push(result)
+				a.NotError(db.Select(v))
+				rec.Reset()
+			}
+		}
+	}
+
+	b.Run("reflect", bench(&reflectArticle{ID: 1}))
+	b.Run("accessor", bench(&modeltest.Article{ID: 1}))
+}
+
 // go1.10 BenchmarkNewModelNoCached-4   	  200000	      8161 ns/op
 func BenchmarkNewModelNoCached(b *testing.B) {
 	orm.ClearModels()
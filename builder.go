@@ -0,0 +1,273 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/internal/tags"
+)
+
+// ModelBuilder 用于在代码中声明模型的结构。
+//
+// 适用于无法添加 struct tag 或是无法实现 Metaer 接口的类型，比如其它包中的类型。
+// 各方法与 struct tag 中的同名属性相对应，按调用的顺序依次生效，
+// 一旦出错，之后的调用都将被忽略，错误由 Register() 返回：
+//  m, err := orm.NewModelBuilder(&other.User{}, "users").
+//      Column("ID", "id").AI("id").
+//      Column("Name", "name").Len("name", 20).Unique("unique_name", "name").
+//      Column("Group", "group").FK("fk_group", "group", "#groups", "id").
+//      Check("chk_id", "id>0").
+//      Option("mysql_engine", "innodb").
+//      Register()
+//
+// 只有通过 Column() 声明的字段才会被当作列，字段中的 struct tag 将被忽略。
+type ModelBuilder struct {
+	m     *Model
+	rtype reflect.Type
+	err   error
+}
+
+// NewModelBuilder 声明一个用于描述 obj 的 ModelBuilder 实例。
+//
+// obj 可以是一个 struct 实例或是指针；table 为表名，不需要包含表名前缀。
+func NewModelBuilder(obj interface{}, table string) *ModelBuilder {
+	rtype := reflect.TypeOf(obj)
+	for rtype != nil && rtype.Kind() == reflect.Ptr {
+		rtype = rtype.Elem()
+	}
+
+	b := &ModelBuilder{
+		m:     newModel(table),
+		rtype: rtype,
+	}
+
+	switch {
+	case rtype == nil || rtype.Kind() != reflect.Struct:
+		b.err = fetch.ErrInvalidKind
+	case table == "":
+		b.err = errors.New("未指定表名")
+	}
+
+	return b
+}
+
+// Column 将字段 field 声明为名称为 name 的列。
+//
+// field 可以是匿名字段中的字段；
+// 之后的 Len()、PK() 等方法都通过 name 引用该列。
+func (b *ModelBuilder) Column(field, name string) *ModelBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	f, found := b.rtype.FieldByName(field)
+	switch {
+	case !found:
+		b.err = fmt.Errorf("%s 中不存在字段 %s", b.rtype.Name(), field)
+	case f.PkgPath != "":
+		b.err = fmt.Errorf("%s.%s 为不可导出的字段", b.rtype.Name(), field)
+	case name == "":
+		b.err = fmt.Errorf("%s.%s 未指定列名", b.rtype.Name(), field)
+	case b.m.Cols[name] != nil:
+		b.err = fmt.Errorf("%s.%s 的列名 %s 已经存在", b.rtype.Name(), field, name)
+	case b.hasField(f.Name):
+		b.err = fmt.Errorf("%s.%s 已经声明为列", b.rtype.Name(), field)
+	default:
+		col := b.m.newColumn(f)
+		col.Name = name
+		b.m.Cols[name] = col
+	}
+
+	return b
+}
+
+func (b *ModelBuilder) hasField(name string) bool {
+	for _, col := range b.m.Cols {
+		if col.GoName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 对 cols 中的各列依次调用 f，prop 为属性名称，仅用于错误信息。
+func (b *ModelBuilder) apply(prop string, cols []string, f func(*Column) error) *ModelBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if len(cols) == 0 {
+		b.err = fmt.Errorf("%s 属性未指定列", prop)
+		return b
+	}
+
+	for _, name := range cols {
+		col, found := b.m.Cols[name]
+		if !found {
+			b.err = fmt.Errorf("%s 属性中的列 %s 不存在，请先通过 Column() 声明", prop, name)
+			return b
+		}
+
+		if err := f(col); err != nil {
+			b.err = fmt.Errorf("%s.%s 的 %s 属性发生错误: %v", b.rtype.Name(), col.GoName, prop, err)
+			return b
+		}
+	}
+
+	return b
+}
+
+// Len 指定列的长度，相当于 struct tag 中的 len(l1,l2)
+func (b *ModelBuilder) Len(col string, lens ...int) *ModelBuilder {
+	vals := make([]string, 0, len(lens))
+	for _, l := range lens {
+		vals = append(vals, strconv.Itoa(l))
+	}
+
+	return b.apply("len", []string{col}, func(c *Column) error {
+		return c.setLen(vals)
+	})
+}
+
+// Nullable 将列指定为允许 NULL，相当于 struct tag 中的 nullable
+func (b *ModelBuilder) Nullable(col string) *ModelBuilder {
+	return b.apply("nullable", []string{col}, func(c *Column) error {
+		return c.setNullable(nil)
+	})
+}
+
+// Default 指定列的默认值，相当于 struct tag 中的 default(val)
+func (b *ModelBuilder) Default(col, val string) *ModelBuilder {
+	return b.apply("default", []string{col}, func(c *Column) error {
+		return b.m.setDefault(c, []string{val})
+	})
+}
+
+// AI 将列指定为自增列，相当于 struct tag 中的 ai
+func (b *ModelBuilder) AI(col string) *ModelBuilder {
+	return b.apply("ai", []string{col}, func(c *Column) error {
+		return b.m.setAI(c, nil)
+	})
+}
+
+// PK 将 cols 指定为主键，相当于在各列的 struct tag 中指定 pk
+func (b *ModelBuilder) PK(cols ...string) *ModelBuilder {
+	return b.apply("pk", cols, func(c *Column) error {
+		return b.m.setPK(c, nil)
+	})
+}
+
+// Index 将 cols 指定为名称为 name 的索引，相当于在各列的 struct tag 中指定 index(name)
+func (b *ModelBuilder) Index(name string, cols ...string) *ModelBuilder {
+	return b.apply("index", cols, func(c *Column) error {
+		return b.m.setIndex(c, []string{name})
+	})
+}
+
+// Unique 将 cols 指定为名称为 name 的唯一约束，相当于在各列的 struct tag 中指定 unique(name)
+func (b *ModelBuilder) Unique(name string, cols ...string) *ModelBuilder {
+	return b.apply("unique", cols, func(c *Column) error {
+		return b.m.setUnique(c, []string{name})
+	})
+}
+
+// FK 为列 col 指定外键，相当于 struct tag 中的 fk(name,refTable,refCol,updateRule,deleteRule)
+//
+// rules 依次为 updateRule 和 deleteRule，不指定则使用数据库的默认值。
+func (b *ModelBuilder) FK(name, col, refTable, refCol string, rules ...string) *ModelBuilder {
+	return b.apply("fk", []string{col}, func(c *Column) error {
+		if len(rules) > 2 {
+			return errors.New("过多的参数")
+		}
+		return b.m.setFK(c, append([]string{name, refTable, refCol}, rules...))
+	})
+}
+
+// OCC 将列指定为乐观锁，相当于 struct tag 中的 occ
+func (b *ModelBuilder) OCC(col string) *ModelBuilder {
+	return b.apply("occ", []string{col}, func(c *Column) error {
+		return b.m.setOCC(c, nil)
+	})
+}
+
+// Tenant 将列指定为租户列，相当于 struct tag 中的 tenant
+func (b *ModelBuilder) Tenant(col string) *ModelBuilder {
+	return b.apply("tenant", []string{col}, func(c *Column) error {
+		return b.m.setTenant(c, nil)
+	})
+}
+
+// Check 添加 check 约束，相当于 Metaer 中的 check(name,expr)
+func (b *ModelBuilder) Check(name, expr string) *ModelBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if err := b.m.setMeta(&tags.Tag{Name: "check", Args: []string{name, expr}}); err != nil {
+		b.err = fmt.Errorf("%s 的 check 属性发生错误: %v", b.rtype.Name(), err)
+	}
+	return b
+}
+
+// Option 指定表级别的属性，比如 mysql_engine 和 sqlite3_rowid 等，
+// 相当于 Metaer 中的自定义属性，会被保存到 Model.Meta 中。
+//
+// 表名和 check 约束应该分别通过 NewModelBuilder() 和 Check() 指定。
+func (b *ModelBuilder) Option(name string, vals ...string) *ModelBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	if name == "name" || name == "check" {
+		b.err = fmt.Errorf("不能通过 Option() 指定 %s 属性", name)
+		return b
+	}
+
+	b.m.Meta[name] = vals
+	return b
+}
+
+// Register 注册模型。
+//
+// 注册之后，NewModel() 会直接返回该模型，DB 和 Tx 中的各类操作，
+// 以及 fetch 包中的导出功能都会按注册的内容处理该类型。
+// 同一类型只能注册一次。
+func (b *ModelBuilder) Register() (*Model, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if len(b.m.Cols) == 0 {
+		return nil, fmt.Errorf("%s 未声明任何列", b.rtype.Name())
+	}
+
+	index, err := accessorIndex(b.m, b.rtype)
+	if err != nil {
+		return nil, err
+	}
+	b.m.accessor = index
+
+	models.Lock()
+	defer models.Unlock()
+
+	if _, found := models.registered[b.rtype]; found {
+		return nil, fmt.Errorf("%s 已经注册", b.rtype.Name())
+	}
+
+	names := make(map[string]string, len(b.m.Cols))
+	for _, col := range b.m.Cols {
+		names[col.GoName] = col.Name
+	}
+	fetch.RegisterNames(b.rtype, names)
+
+	delete(models.items, b.rtype) // 可能已经通过 struct tag 生成过
+	models.registered[b.rtype] = b.m
+	return b.m, nil
+}
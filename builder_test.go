@@ -0,0 +1,170 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/ormtest"
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+// 模拟其它包中无法添加 struct tag 的类型
+type plainBase struct {
+	ID int64
+}
+
+type plainUser struct {
+	plainBase
+	Name    string
+	Email   string
+	Version int64
+	Note    string `orm:"name(note)"` // 未通过 Column() 声明，会被忽略
+}
+
+var registerPlainOnce sync.Once
+
+func registerPlain(a *assert.Assertion) {
+	registerPlainOnce.Do(func() {
+		m, err := orm.NewModelBuilder(&plainUser{}, "plain_users").
+			Column("ID", "id").AI("id").
+			Column("Name", "name").Len("name", 20).Unique("unique_plain_name", "name").
+			Column("Email", "email").Len("email", 50).Default("email", "a,b").Index("index_plain_email", "email").
+			Column("Version", "version").Default("version", "1").OCC("version").
+			Check("chk_plain_id", "id>0").
+			Option("mysql_engine", "innodb").
+			Register()
+		a.NotError(err).NotNil(m)
+	})
+}
+
+func TestModelBuilder(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := orm.NewModelBuilder(5, "table").Register()
+	a.Error(err)
+
+	_, err = orm.NewModelBuilder(&plainUser{}, "").Register()
+	a.Error(err)
+
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Register() // 未声明列
+	a.Error(err)
+
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("NotExists", "id").Register()
+	a.Error(err)
+
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Column("Name", "id").Register()
+	a.Error(err)
+
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Column("ID", "id2").Register()
+	a.Error(err)
+
+	// 引用了未声明的列
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").PK("id", "name").Register()
+	a.Error(err)
+
+	// 与 struct tag 相同的检测
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").AI("id").PK("id").Register()
+	a.Error(err)
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Len("id", 1, 2, 3).Register()
+	a.Error(err)
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Check("chk", "id>0").Check("chk", "id>1").Register()
+	a.Error(err)
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Option("name", "t2").Register()
+	a.Error(err)
+
+	registerPlain(a)
+
+	// 不能重复注册
+	_, err = orm.NewModelBuilder(&plainUser{}, "t").Column("ID", "id").Register()
+	a.Error(err)
+
+	// ClearModels() 不会清除注册的模型
+	orm.ClearModels()
+	m, err := orm.NewModel(&plainUser{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Name, "plain_users").
+		Equal(len(m.Cols), 4).
+		Equal(m.AI, m.Cols["id"]).
+		Equal(m.OCC, m.Cols["version"]).
+		Equal(m.Cols["email"].Default, "a,b").
+		Equal(m.Check["chk_plain_id"], "id>0").
+		Equal(m.Meta["mysql_engine"], []string{"innodb"})
+	a.Equal(m.Cols["id"].GoName, "ID")
+}
+
+func TestModelBuilder_DDL(t *testing.T) {
+	a := assert.New(t)
+	registerPlain(a)
+
+	db, rec := ormtest.New(dialect.Mysql(), "p_")
+	defer db.Close()
+
+	a.NotError(db.Create(&plainUser{}))
+	queries := strings.Join(rec.Queries(), "\n")
+	a.Contains(queries, "CREATE TABLE IF NOT EXISTS `p_plain_users`").
+		Contains(queries, "`email` VARCHAR(50) NOT NULL DEFAULT 'a,b'").
+		Contains(queries, "unique_plain_name").
+		Contains(queries, "chk_plain_id").
+		Contains(queries, "index_plain_email").
+		NotContains(queries, "note")
+}
+
+func TestModelBuilder_CRUD(t *testing.T) {
+	a := assert.New(t)
+	registerPlain(a)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&plainUser{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+	a.NotError(db.Create(&plainUser{}))
+
+	_, err := db.Insert(&plainUser{Name: "u1", Email: "e1", Note: "note"})
+	a.NotError(err)
+	_, err = db.Insert(&plainUser{Name: "u2"})
+	a.NotError(err)
+
+	u := &plainUser{plainBase: plainBase{ID: 1}}
+	a.NotError(db.Select(u))
+	a.Equal(u, &plainUser{plainBase: plainBase{ID: 1}, Name: "u1", Email: "e1", Version: 1})
+
+	// 默认值
+	u = &plainUser{Name: "u2"}
+	a.NotError(db.Select(u))
+	a.Equal(u.ID, 2).Equal(u.Email, "a,b")
+
+	// 乐观锁
+	u = &plainUser{plainBase: plainBase{ID: 1}}
+	a.NotError(db.Select(u))
+	a.Equal(u.Version, 1)
+	u.Email = "e1-1"
+	_, err = db.Update(u)
+	a.NotError(err).Equal(u.Version, 2)
+	u = &plainUser{plainBase: plainBase{ID: 1}}
+	a.NotError(db.Select(u))
+	a.Equal(u.Email, "e1-1").Equal(u.Version, 2)
+
+	// 通过 fetch 导出
+	users := []*plainUser{}
+	cnt, err := sqlbuilder.Select(db, db.Dialect()).
+		Select("*").
+		From("{#plain_users}").
+		Desc("id").
+		QueryObj(&users)
+	a.NotError(err).Equal(cnt, 2)
+	a.Equal(users[0].Name, "u2").Equal(users[1].Name, "u1")
+
+	_, err = db.Delete(&plainUser{plainBase: plainBase{ID: 1}})
+	a.NotError(err)
+	hasCount(db, a, "plain_users", 1)
+}
@@ -0,0 +1,230 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+// BulkIterator 为 BulkLoad 依次提供需要导入的对象。
+type BulkIterator interface {
+	// 返回下一个需要导入的对象，所有对象的类型必须相同。
+	//
+	// 若已经没有数据，则返回 nil, nil。
+	Next() (interface{}, error)
+}
+
+// BulkLoader 可由 Dialect 实现，提供数据库特有的大批量数据导入方式，
+// 比如 postgresql 的 COPY FROM STDIN 和 mysql 的 LOAD DATA LOCAL INFILE。
+//
+// 未实现该接口的 Dialect，在 BulkLoad 时会采用在事务中分批执行预编译的多行插入语句的方式。
+type BulkLoader interface {
+	// 将数据导入到表 table 中。
+	//
+	// table 和 cols 均为未作转换的名称，在生成语句时需要自行加上 {# 和 }；
+	// next 依次返回与 cols 对应的每一行数据，返回 nil, nil 表示已经没有数据。
+	// 返回值表示实际导入的数量。
+	BulkLoad(tx *Tx, table string, cols []string, next func() ([]interface{}, error)) (int64, error)
+}
+
+// 将数组包装成 BulkIterator
+type sliceIterator struct {
+	rval  reflect.Value
+	index int
+}
+
+func (s *sliceIterator) Next() (interface{}, error) {
+	if s.index >= s.rval.Len() {
+		return nil, nil
+	}
+
+	v := s.rval.Index(s.index)
+	s.index++
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		v = v.Addr()
+	}
+	return v.Interface(), nil
+}
+
+func newBulkIterator(v interface{}) (BulkIterator, error) {
+	if iter, ok := v.(BulkIterator); ok {
+		return iter, nil
+	}
+
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		rval = rval.Elem()
+	}
+
+	switch rval.Kind() {
+	case reflect.Array, reflect.Slice:
+		return &sliceIterator{rval: rval}, nil
+	default:
+		return nil, fetch.ErrInvalidKind
+	}
+}
+
+// 批量导入 v 中的数据，v 可以是由对象组成的数组，或是 BulkIterator 接口。
+//
+// 除自增列之外的所有列都会被导入，即使其值为零值。
+func bulkLoad(tx *Tx, v interface{}) (int64, error) {
+	iter, err := newBulkIterator(v)
+	if err != nil {
+		return 0, err
+	}
+
+	first, err := iter.Next()
+	if err != nil || first == nil {
+		return 0, err
+	}
+
+	m, rval, err := getModel(first)
+	if err != nil {
+		return 0, err
+	}
+	firstType := rval.Type()
+
+	cols := make([]*Column, 0, len(m.Cols))
+	for _, col := range m.Cols {
+		if !col.IsAI() {
+			cols = append(cols, col)
+		}
+	}
+	sort.SliceStable(cols, func(i, j int) bool { return cols[i].Name < cols[j].Name })
+
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
+
+	obj := first
+	next := func() ([]interface{}, error) {
+		if obj == nil {
+			if obj, err = iter.Next(); err != nil || obj == nil {
+				return nil, err
+			}
+		}
+		v := obj
+		obj = nil
+
+		if o, ok := v.(BeforeInserter); ok {
+			if err := o.BeforeInsert(); err != nil {
+				return nil, err
+			}
+		}
+
+		rval := reflect.ValueOf(v)
+		for rval.Kind() == reflect.Ptr {
+			rval = rval.Elem()
+		}
+		if rval.Type() != firstType {
+			return nil, errors.New("参数 v 中包含了不同类型的元素")
+		}
+
+		if err := applyTenant(tx, m, rval); err != nil {
+			return nil, err
+		}
+
+		cv := newColumnValues(m, v, rval)
+		vals := make([]interface{}, 0, len(cols))
+		for _, col := range cols {
+			val, err := cv.get(col)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return vals, nil
+	}
+
+	if loader, ok := tx.Dialect().(BulkLoader); ok {
+		return loader.BulkLoad(tx, m.Name, names, next)
+	}
+	return bulkInsert(tx, m.Name, names, next)
+}
+
+// bulkInsert 中每条语句最多包含的行数，即使 Dialect.MaxArgs() 允许更多的行。
+const maxBulkInsertRows = 1000
+
+// 通过预编译的多行插入语句分批导入数据，适用于未实现 BulkLoader 的 Dialect。
+//
+// 每条语句包含的行数由 Dialect.MaxArgs() 决定，最后不足一批的数据则以一条较短的语句插入。
+func bulkInsert(tx *Tx, table string, cols []string, next func() ([]interface{}, error)) (int64, error) {
+	size := batchSize(tx.Dialect(), len(cols))
+	if size > maxBulkInsertRows {
+		size = maxBulkInsertRows
+	}
+
+	var stmt *sql.Stmt
+	defer func() {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}()
+
+	var count int64
+	rows := make([][]interface{}, 0, size)
+	for {
+		vals, err := next()
+		if err != nil {
+			return count, err
+		}
+		if vals == nil {
+			break
+		}
+
+		if rows = append(rows, vals); len(rows) < size {
+			continue
+		}
+
+		if stmt == nil { // 仅在数据量达到一批时才需要预编译
+			if stmt, err = bulkInsertStmt(tx, table, cols, make([][]interface{}, size)).Prepare(); err != nil {
+				return count, err
+			}
+		}
+
+		args := make([]interface{}, 0, size*len(cols))
+		for _, row := range rows {
+			args = append(args, row...)
+		}
+		if _, err = stmt.Exec(args...); err != nil {
+			return count, err
+		}
+		count += int64(len(rows))
+		rows = rows[:0]
+	}
+
+	if len(rows) == 0 {
+		return count, nil
+	}
+
+	if _, err := bulkInsertStmt(tx, table, cols, rows).Exec(); err != nil {
+		return count, err
+	}
+	return count + int64(len(rows)), nil
+}
+
+// 生成插入 rows 中所有数据的语句，rows 中为 nil 的元素表示仅生成占位符。
+func bulkInsertStmt(tx *Tx, table string, cols []string, rows [][]interface{}) *sqlbuilder.InsertStmt {
+	sql := tx.SQL().Insert().Table("{#" + table + "}")
+	for _, col := range cols {
+		sql.Columns("{" + col + "}")
+	}
+
+	for _, row := range rows {
+		if row == nil {
+			row = make([]interface{}, len(cols))
+		}
+		sql.Values(row...)
+	}
+
+	return sql
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/ormtest"
+)
+
+type groupIterator struct {
+	count, max int
+}
+
+func (g *groupIterator) Next() (interface{}, error) {
+	if g.count >= g.max {
+		return nil, nil
+	}
+	g.count++
+
+	return &modeltest.Group{Name: "iter" + strconv.Itoa(g.count)}, nil
+}
+
+func TestDB_BulkLoad(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Group{}))
+		clearData(db, a)
+	}()
+	a.NotError(db.Create(&modeltest.Group{}))
+
+	// 数组
+	gs := make([]modeltest.Group, 0, 100)
+	for i := 0; i < 100; i++ {
+		gs = append(gs, modeltest.Group{Name: "slice" + strconv.Itoa(i)})
+	}
+	count, err := db.BulkLoad(gs)
+	a.NotError(err).Equal(count, 100)
+	hasCount(db, a, "groups", 100)
+
+	// BulkIterator，超过一批的数据量
+	count, err = db.BulkLoad(&groupIterator{max: 600})
+	a.NotError(err).Equal(count, 600)
+	hasCount(db, a, "groups", 700)
+
+	// 空数据
+	count, err = db.BulkLoad([]*modeltest.Group{})
+	a.NotError(err).Equal(count, 0)
+
+	// 不同类型的元素，整个事务回滚
+	count, err = db.BulkLoad([]interface{}{&modeltest.Group{Name: "g1"}, &modeltest.UserInfo{UID: 1}})
+	a.Error(err).Equal(count, 0)
+	hasCount(db, a, "groups", 700)
+
+	// 无效的类型
+	_, err = db.BulkLoad(5)
+	a.Error(err)
+}
+
+func TestDB_BulkLoad_batch(t *testing.T) {
+	a := assert.New(t)
+
+	db, rec := ormtest.New(dialect.Sqlite3(), "")
+	defer func() {
+		a.NotError(db.Close())
+	}()
+
+	// sqlite3 最多 999 个参数，每行 2 个参数，每批 499 行
+	count, err := db.BulkLoad(&groupIterator{max: 1000})
+	a.NotError(err).Equal(count, 1000)
+
+	stmts := rec.Statements()
+	a.Equal(len(stmts), 3)
+	a.Equal(len(stmts[0].Args), 998).
+		Equal(len(stmts[1].Args), 998).
+		Equal(len(stmts[2].Args), 4)
+	a.Equal(stmts[2].Args, []interface{}{int64(0), "iter999", int64(0), "iter1000"})
+	a.True(ormtest.Equal(stmts[2].Query, "INSERT INTO `groups` (`created`,`name`) VALUES (?,?),(?,?)"), stmts[2].Query)
+}
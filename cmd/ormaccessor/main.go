@@ -0,0 +1,301 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// ormaccessor 为模型生成不依赖反射的访问方法。
+//
+// 会根据模型的 struct tag 为每个模型生成 OrmColumns()、OrmValues()、
+// OrmPK() 和 OrmScanTargets() 四个方法，分别实现了 orm.Accessor
+// 和 fetch.ScanTargeter 接口，orm 在操作这些模型时不再通过反射查找字段。
+// 一般通过 go generate 调用：
+//  //go:generate ormaccessor -types User,Group -o accessor.go
+//
+// 嵌入的结构体必须与模型声明在同一个包中。
+// 修改了模型的结构之后需要重新生成，否则 orm.NewModel() 会返回错误。
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/issue9/orm/internal/tags"
+)
+
+var tpl = template.Must(template.New("accessor").Parse(`// 由 ormaccessor 生成，请勿手动修改。
+
+package {{.Pkg}}
+{{range .Models}}
+// OrmColumns 实现 orm.Accessor 接口
+func (m *{{.Name}}) OrmColumns() []string {
+	return []string{
+	{{- range .Columns}}
+		{{printf "%q" .Name}},
+	{{- end}}
+	}
+}
+
+// OrmValues 实现 orm.Accessor 接口
+func (m *{{.Name}}) OrmValues() []interface{} {
+	return []interface{}{
+	{{- range .Columns}}
+		m.{{.Field}},
+	{{- end}}
+	}
+}
+
+// OrmPK 实现 orm.Accessor 接口
+func (m *{{.Name}}) OrmPK() []interface{} {
+	return []interface{}{
+	{{- range .PK}}
+		m.{{.Field}},
+	{{- end}}
+	}
+}
+
+// OrmScanTargets 实现 fetch.ScanTargeter 接口
+func (m *{{.Name}}) OrmScanTargets(cols []string) []interface{} {
+	targets := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		switch col {
+		{{- range .Columns}}
+		case {{printf "%q" .Name}}:
+			targets = append(targets, &m.{{.Field}})
+		{{- end}}
+		default:
+			var v interface{}
+			targets = append(targets, &v)
+		}
+	}
+	return targets
+}
+{{end}}`))
+
+type options struct {
+	Dir    string
+	Types  []string
+	Output string
+}
+
+type model struct {
+	Name    string
+	Columns []*column
+	PK      []*column
+}
+
+type column struct {
+	Name  string // 列名
+	Field string // 字段名
+	pk    bool
+	ai    bool
+}
+
+func main() {
+	dir := flag.String("dir", ".", "模型所在的目录")
+	types := flag.String("types", "", "需要生成的模型类型名称，多个以逗号分隔")
+	output := flag.String("o", "orm_accessor.go", "输出的文件，相对路径表示相对于 -dir 指定的目录")
+	flag.Parse()
+
+	opt, err := newOptions(*dir, *types, *output)
+	if err != nil {
+		exit(err)
+	}
+
+	src, err := run(opt)
+	if err != nil {
+		exit(err)
+	}
+
+	path := opt.Output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(opt.Dir, path)
+	}
+	if err = os.WriteFile(path, src, 0644); err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+func newOptions(dir, types, output string) (*options, error) {
+	if output == "" {
+		return nil, errors.New("未指定 -o 参数")
+	}
+
+	opt := &options{Dir: dir, Output: output}
+	for _, typ := range strings.Split(types, ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			opt.Types = append(opt.Types, typ)
+		}
+	}
+	if len(opt.Types) == 0 {
+		return nil, errors.New("未指定 -types 参数")
+	}
+
+	return opt, nil
+}
+
+// 分析 opt.Dir 中的包，并生成代码。
+func run(opt *options) ([]byte, error) {
+	filter := func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != filepath.Base(opt.Output)
+	}
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), opt.Dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s 中包含了 %d 个包", opt.Dir, len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	structs := map[string]*ast.StructType{}
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	models := make([]*model, 0, len(opt.Types))
+	for _, name := range opt.Types {
+		st, found := structs[name]
+		if !found {
+			return nil, fmt.Errorf("未找到结构体 %s", name)
+		}
+
+		m := &model{Name: name}
+		if err := m.parseColumns(structs, st); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		m.setPK()
+		models = append(models, m)
+	}
+
+	buf := new(bytes.Buffer)
+	err = tpl.Execute(buf, map[string]interface{}{
+		"Pkg":    pkg.Name,
+		"Models": models,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// 与 orm.NewModel() 相同的规则分析 st 中的列，支持匿名字段。
+func (m *model) parseColumns(structs map[string]*ast.StructType, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			val, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(val).Get("orm")
+		}
+
+		if len(field.Names) == 0 { // 匿名字段
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("不支持的嵌入类型 %s", exprString(field.Type))
+			}
+
+			embedded, found := structs[ident.Name]
+			if !found {
+				return fmt.Errorf("未找到嵌入的结构体 %s", ident.Name)
+			}
+
+			if err := m.parseColumns(structs, embedded); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		for _, name := range field.Names {
+			if unicode.IsLower(rune(name.Name[0])) { // 忽略以小写字母开头的字段
+				continue
+			}
+
+			col := &column{
+				Name:  name.Name,
+				Field: name.Name,
+			}
+			vals, found, err := tags.Get(tag, "name")
+			if err != nil {
+				return fmt.Errorf("%s: %v", name.Name, err)
+			}
+			if found && len(vals) == 1 {
+				col.Name = vals[0]
+			}
+			if col.pk, err = tags.Has(tag, "pk"); err != nil {
+				return fmt.Errorf("%s: %v", name.Name, err)
+			}
+			if col.ai, err = tags.Has(tag, "ai"); err != nil {
+				return fmt.Errorf("%s: %v", name.Name, err)
+			}
+			m.Columns = append(m.Columns, col)
+		}
+	}
+
+	return nil
+}
+
+// 与 orm.NewModel() 相同的规则确定主键：自增列即为主键，否则为所有 pk 列。
+func (m *model) setPK() {
+	for _, col := range m.Columns {
+		if col.ai {
+			m.PK = []*column{col}
+			return
+		}
+	}
+
+	for _, col := range m.Columns {
+		if col.pk {
+			m.PK = append(m.PK, col)
+		}
+	}
+}
+
+// 返回类型表达式的字符串形式，仅用于错误信息。
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+const modeltestDir = "../../internal/modeltest"
+
+func TestNewOptions(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions(".", "User, Group,", "accessor.go")
+	a.NotError(err).NotNil(opt)
+	a.Equal(opt.Types, []string{"User", "Group"})
+
+	_, err = newOptions(".", " ", "accessor.go")
+	a.Error(err)
+	_, err = newOptions(".", "User", "")
+	a.Error(err)
+}
+
+func TestRun(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions(modeltestDir, "Admin,UserInfo", "accessor.go")
+	a.NotError(err)
+	src, err := run(opt)
+	a.NotError(err)
+	code := string(src)
+
+	a.Contains(code, "package modeltest")
+
+	// Admin 嵌入了 User，忽略了 Regdate
+	a.Contains(code, "func (m *Admin) OrmColumns() []string {\n\treturn []string{\n\t\t\"id\",\n\t\t\"Username\",\n\t\t\"password\",\n\t\t\"email\",\n\t\t\"group\",\n\t}\n}")
+	a.NotContains(code, "Regdate")
+	a.Contains(code, "case \"group\":\n\t\t\ttargets = append(targets, &m.Group)")
+
+	a.Contains(code, "func (m *UserInfo) OrmValues() []interface{} {")
+
+	// 自增列即为主键，否则为 pk 列
+	a.Contains(code, "func (m *Admin) OrmPK() []interface{} {\n\treturn []interface{}{\n\t\tm.ID,\n\t}\n}")
+	a.Contains(code, "func (m *UserInfo) OrmPK() []interface{} {\n\treturn []interface{}{\n\t\tm.UID,\n\t}\n}")
+
+	// 不存在的类型
+	opt, err = newOptions(modeltestDir, "NotExists", "accessor.go")
+	a.NotError(err)
+	_, err = run(opt)
+	a.Error(err)
+}
+
+// modeltest 中生成的代码应该与当前的生成结果一致
+func TestRun_upToDate(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions(modeltestDir, "Article", "article_accessor.go")
+	a.NotError(err)
+	src, err := run(opt)
+	a.NotError(err)
+
+	content, err := os.ReadFile(modeltestDir + "/article_accessor.go")
+	a.NotError(err)
+	a.Equal(string(src), string(content))
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// ormgen 根据已有的数据库表结构生成对应的 Go 模型代码。
+//
+// 会为每一张表生成一个结构体以及指定了表名和 check 约束的 Meta() 方法，
+// 列的类型、长度、主键、自增、唯一约束、索引、外键以及默认值等，
+// 都会以 struct tag 的形式写入到对应的字段中：
+//  ormgen -driver sqlite3 -dsn ./app.db -pkg models -prefix p_ -o models.go
+//
+// 指定了 -prefix 之后，只会处理以该前缀开头的表，且生成的表名会去掉该前缀；
+// 包含括号、逗号或是引号的默认值和 check 约束，会以单引号包含之后写入 struct tag；
+// 无法通过 struct tag 表达的内容，比如函数调用等非字面量的默认值，
+// 以及包含反引号的默认值和 check 约束，不会出现在生成的代码中，
+// 而是以注释的形式列在结构体的文档中。
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+	"text/template"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var inspectors = map[string]inspector{
+	"mysql":    mysql{},
+	"postgres": postgres{},
+	"sqlite3":  sqlite3{},
+}
+
+var tpl = template.Must(template.New("models").Parse(`// 由 ormgen 生成。
+
+package {{.Pkg}}
+{{if .Imports}}
+import (
+{{- range .Imports}}
+	{{printf "%q" .}}
+{{- end}}
+)
+{{end}}
+{{- range .Models}}
+// {{.Name}} 对应数据表 {{.Table}}
+{{- if .Ignored}}
+//
+// 以下内容无法通过 struct tag 表达，未包含在生成的代码中：
+{{- range .Ignored}}
+//  {{.}}
+{{- end}}
+{{- end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `orm:{{printf "%q" .Tag}}` + "`" + `
+{{- end}}
+}
+
+// Meta 指定表属性
+func (m *{{.Name}}) Meta() string {
+	return {{printf "%q" .Meta}}
+}
+{{end}}`))
+
+type options struct {
+	Driver string
+	DSN    string
+	Pkg    string
+	Tables []string
+	Prefix string
+
+	inspector inspector
+}
+
+func main() {
+	driver := flag.String("driver", "mysql", "数据库类型，可以是 mysql、postgres 和 sqlite3")
+	dsn := flag.String("dsn", "", "连接数据库的 DSN")
+	pkg := flag.String("pkg", "models", "生成的代码所在的包名")
+	tables := flag.String("tables", "", "需要生成的表名，多个以逗号分隔，为空表示所有的表")
+	prefix := flag.String("prefix", "", "表名前缀")
+	output := flag.String("o", "", "输出的文件，为空表示输出到终端")
+	flag.Parse()
+
+	opt, err := newOptions(*driver, *dsn, *pkg, *tables, *prefix)
+	if err != nil {
+		exit(err)
+	}
+
+	db, err := sql.Open(opt.Driver, opt.DSN)
+	if err != nil {
+		exit(err)
+	}
+	defer db.Close()
+
+	src, err := run(db, opt)
+	if err != nil {
+		exit(err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0644)
+	}
+	if err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+func newOptions(driver, dsn, pkg, tables, prefix string) (*options, error) {
+	if dsn == "" {
+		return nil, errors.New("未指定 -dsn 参数")
+	}
+
+	if pkg == "" {
+		return nil, errors.New("未指定 -pkg 参数")
+	}
+
+	opt := &options{Driver: driver, DSN: dsn, Pkg: pkg, Prefix: prefix}
+
+	var found bool
+	if opt.inspector, found = inspectors[driver]; !found {
+		return nil, fmt.Errorf("不支持的数据库类型 %s", driver)
+	}
+
+	for _, name := range strings.Split(tables, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opt.Tables = append(opt.Tables, name)
+		}
+	}
+
+	return opt, nil
+}
+
+// 读取数据库中的表结构，并生成对应的代码。
+func run(db *sql.DB, opt *options) ([]byte, error) {
+	names := opt.Tables
+	if len(names) == 0 {
+		all, err := opt.inspector.tables(db)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range all {
+			if strings.HasPrefix(name, opt.Prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	tables := make([]*table, 0, len(names))
+	for _, name := range names {
+		t, err := opt.inspector.table(db, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+
+	return generate(opt, tables)
+}
+
+func generate(opt *options, tables []*table) ([]byte, error) {
+	g := newGenerator(opt.Prefix)
+	models := make([]*model, 0, len(tables))
+	for _, t := range tables {
+		models = append(models, g.model(t))
+	}
+
+	buf := new(bytes.Buffer)
+	err := tpl.Execute(buf, map[string]interface{}{
+		"Pkg":     opt.Pkg,
+		"Imports": g.importPaths(),
+		"Models":  models,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
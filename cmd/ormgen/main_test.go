@@ -0,0 +1,158 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"database/sql"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/internal/tags"
+)
+
+func TestNewOptions(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions("sqlite3", "./test.db", "models", "p_groups, p_users,", "p_")
+	a.NotError(err).NotNil(opt)
+	a.Equal(opt.Tables, []string{"p_groups", "p_users"}).Equal(opt.inspector, sqlite3{})
+
+	_, err = newOptions("sqlite3", "", "models", "", "")
+	a.Error(err)
+	_, err = newOptions("sqlite3", "./test.db", "", "", "")
+	a.Error(err)
+	_, err = newOptions("oracle", "./test.db", "models", "", "")
+	a.Error(err)
+}
+
+func TestRun(t *testing.T) {
+	a := assert.New(t)
+	dsn := filepath.Join(t.TempDir(), "ormgen.db")
+
+	db, err := orm.NewDB("sqlite3", dsn, "p_", dialect.Sqlite3())
+	a.NotError(err)
+	a.NotError(db.MultCreate(&modeltest.Group{}, &modeltest.Admin{}, &modeltest.UserInfo{}))
+	a.NotError(db.Close())
+
+	sqlDB, err := sql.Open("sqlite3", dsn)
+	a.NotError(err)
+	defer func() {
+		a.NotError(sqlDB.Close())
+	}()
+
+	opt, err := newOptions("sqlite3", dsn, "models", "", "p_")
+	a.NotError(err)
+	src, err := run(sqlDB, opt)
+	a.NotError(err)
+	code := string(src)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "models.go", src, 0)
+	a.NotError(err)
+
+	a.Contains(code, "package models")
+	a.Contains(code, "type Administrators struct")
+	a.Contains(code, "ID       int64  `orm:\"name(id);ai\"`")
+	a.Contains(code, "`orm:\"name(Username);len(-1);unique(unique_username);index(index_name)\"`")
+	a.Contains(code, "`orm:\"name(group);fk(fk_name,#groups,id)\"`")
+	a.Contains(code, `return "name(administrators);check(chk_name,id>0)"`)
+
+	a.Contains(code, "type UserInfo struct")
+	a.Contains(code, "`orm:\"name(uid);pk\"`")
+	a.Contains(code, "`orm:\"name(firstName);len(-1);unique(unique_name)\"`")
+	a.Contains(code, "`orm:\"name(lastName);len(-1);unique(unique_name)\"`")
+	a.Contains(code, "`orm:\"name(sex);len(-1);default(male)\"`")
+
+	a.Contains(code, "type Groups struct")
+	a.Contains(code, `return "name(groups)"`)
+
+	// 仅指定的表
+	opt, err = newOptions("sqlite3", dsn, "models", "p_groups", "p_")
+	a.NotError(err)
+	src, err = run(sqlDB, opt)
+	a.NotError(err)
+	a.Contains(string(src), "type Groups struct").NotContains(string(src), "UserInfo")
+}
+
+func TestExportedName(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(exportedName("user_info"), "UserInfo")
+	a.Equal(exportedName("firstName"), "FirstName")
+	a.Equal(exportedName("uid"), "UID")
+	a.Equal(exportedName("user_id"), "UserID")
+	a.Equal(exportedName("1st"), "X1st")
+
+	names := map[string]int{}
+	a.Equal(uniqueName(names, "Name"), "Name")
+	a.Equal(uniqueName(names, "Name"), "Name2")
+}
+
+func TestLiteral(t *testing.T) {
+	a := assert.New(t)
+
+	val, ok := literal("'male'")
+	a.True(ok).Equal(val, "male")
+
+	val, ok = literal("'it''s'::character varying")
+	a.True(ok).Equal(val, "it's")
+
+	val, ok = literal("-1.5")
+	a.True(ok).Equal(val, "-1.5")
+
+	_, ok = literal("CURRENT_TIMESTAMP")
+	a.False(ok)
+
+	_, ok = literal("'a' || 'b'")
+	a.False(ok)
+}
+
+func TestTagArg(t *testing.T) {
+	a := assert.New(t)
+
+	val, ok := tagArg("male")
+	a.True(ok).Equal(val, "male")
+
+	val, ok = tagArg("a,b")
+	a.True(ok).Equal(val, "'a,b'")
+
+	val, ok = tagArg(`it's\`)
+	a.True(ok).Equal(val, `'it\'s\\'`)
+
+	val, ok = tagArg("")
+	a.True(ok).Equal(val, "''")
+
+	_, ok = tagArg("`")
+	a.False(ok)
+
+	// 与 orm 的解析结果相同
+	for _, v := range []string{"a,b", "(a>0) AND (b>0)", `it's\`, " a;b "} {
+		arg, ok := tagArg(v)
+		a.True(ok)
+		vals, found, err := tags.Get("default("+arg+")", "default")
+		a.NotError(err).True(found).Equal(vals, []string{v})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	a := assert.New(t)
+
+	typ, lens := parseType("VARCHAR(20)")
+	a.Equal(typ, "varchar").Equal(lens, []int{20})
+
+	typ, lens = parseType("DECIMAL(10, 2)")
+	a.Equal(typ, "decimal").Equal(lens, []int{10, 2})
+
+	typ, lens = parseType("INTEGER")
+	a.Equal(typ, "integer").Nil(lens)
+
+	a.Equal(trimParens("((id > 0))"), "id > 0")
+	a.Equal(trimParens("(a > 0) AND (b > 0)"), "(a > 0) AND (b > 0)")
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type mysql struct{}
+
+func (mysql) tables(db *sql.DB) ([]string, error) {
+	return queryStrings(db, `SELECT TABLE_NAME FROM information_schema.TABLES
+	WHERE TABLE_SCHEMA=DATABASE() AND TABLE_TYPE='BASE TABLE' ORDER BY TABLE_NAME`)
+}
+
+func (m mysql) table(db *sql.DB, name string) (*table, error) {
+	t := newTable(name)
+
+	if err := m.columns(db, t); err != nil {
+		return nil, err
+	}
+
+	// mysql 会为外键自动创建同名的索引，所以需要先读取外键。
+	if err := m.foreignKeys(db, t); err != nil {
+		return nil, err
+	}
+
+	if err := m.indexes(db, t); err != nil {
+		return nil, err
+	}
+
+	if err := m.checks(db, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (mysql) columns(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT COLUMN_NAME, DATA_TYPE, CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE,
+	IS_NULLABLE, COLUMN_DEFAULT, EXTRA FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? ORDER BY ORDINAL_POSITION`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		col := &column{}
+		var charLen, precision, scale sql.NullInt64
+		var nullable, extra string
+		var def sql.NullString
+		err := rows.Scan(&col.Name, &col.Type, &charLen, &precision, &scale, &nullable, &def, &extra)
+		if err != nil {
+			return err
+		}
+
+		col.Type = strings.ToLower(col.Type)
+		switch {
+		case charLen.Valid:
+			col.Len = []int{int(charLen.Int64)}
+		case precision.Valid && scale.Valid:
+			col.Len = []int{int(precision.Int64), int(scale.Int64)}
+		}
+
+		col.Nullable = nullable == "YES"
+		col.AI = strings.Contains(strings.ToLower(extra), "auto_increment")
+
+		// COLUMN_DEFAULT 中的字面量并不包含引号，
+		// 表达式则会在 EXTRA 中标记为 DEFAULT_GENERATED。
+		if def.Valid {
+			col.HasDefault = true
+			col.Default = def.String
+			if !strings.Contains(strings.ToUpper(extra), "DEFAULT_GENERATED") {
+				col.Default = "'" + strings.Replace(def.String, "'", "''", -1) + "'"
+			}
+		}
+
+		t.Columns = append(t.Columns, col)
+	}
+
+	return rows.Err()
+}
+
+func (mysql) indexes(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME FROM information_schema.STATISTICS
+	WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME=? ORDER BY INDEX_NAME, SEQ_IN_INDEX`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, col string
+		var nonUnique bool
+		if err := rows.Scan(&name, &nonUnique, &col); err != nil {
+			return err
+		}
+
+		switch {
+		case name == "PRIMARY":
+			t.PK = append(t.PK, col)
+		case t.FKs[name] != nil:
+			continue
+		case nonUnique:
+			t.Indexes[name] = append(t.Indexes[name], col)
+		default:
+			t.Uniques[name] = append(t.Uniques[name], col)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (mysql) foreignKeys(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+	r.UPDATE_RULE, r.DELETE_RULE FROM information_schema.KEY_COLUMN_USAGE k
+	JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+	ON r.CONSTRAINT_SCHEMA=k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME=k.CONSTRAINT_NAME
+	WHERE k.TABLE_SCHEMA=DATABASE() AND k.TABLE_NAME=? ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	composite := map[string]bool{}
+	for rows.Next() {
+		var name string
+		fk := &foreignKey{}
+		err := rows.Scan(&name, &fk.Column, &fk.RefTable, &fk.RefColumn, &fk.UpdateRule, &fk.DeleteRule)
+		if err != nil {
+			return err
+		}
+
+		if _, found := t.FKs[name]; found {
+			composite[name] = true
+		}
+		t.FKs[name] = fk
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for name := range composite {
+		t.Ignored = append(t.Ignored, fmt.Sprintf("外键 %s 包含多个列", name))
+		delete(t.FKs, name)
+	}
+
+	return nil
+}
+
+// check 约束需要 8.0.16 及之后的版本，之前的版本会忽略 check 约束。
+func (mysql) checks(db *sql.DB, t *table) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.TABLES
+	WHERE TABLE_SCHEMA='information_schema' AND TABLE_NAME='CHECK_CONSTRAINTS'`).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT c.CONSTRAINT_NAME, c.CHECK_CLAUSE FROM information_schema.CHECK_CONSTRAINTS c
+	JOIN information_schema.TABLE_CONSTRAINTS tc
+	ON tc.CONSTRAINT_SCHEMA=c.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME=c.CONSTRAINT_NAME
+	WHERE tc.TABLE_SCHEMA=DATABASE() AND tc.TABLE_NAME=? AND tc.CONSTRAINT_TYPE='CHECK'`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, expr string
+		if err := rows.Scan(&name, &expr); err != nil {
+			return err
+		}
+		t.Checks[name] = strings.Replace(expr, "`", "", -1)
+	}
+
+	return rows.Err()
+}
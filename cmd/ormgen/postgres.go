@@ -0,0 +1,174 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// pg_constraint 中 confupdtype 和 confdeltype 对应的规则
+var postgresRules = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+type postgres struct{}
+
+func (postgres) tables(db *sql.DB) ([]string, error) {
+	return queryStrings(db, `SELECT table_name FROM information_schema.tables
+	WHERE table_schema=current_schema() AND table_type='BASE TABLE' ORDER BY table_name`)
+}
+
+func (p postgres) table(db *sql.DB, name string) (*table, error) {
+	t := newTable(name)
+
+	if err := p.columns(db, t); err != nil {
+		return nil, err
+	}
+
+	if err := p.constraints(db, t); err != nil {
+		return nil, err
+	}
+
+	if err := p.indexes(db, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (postgres) columns(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT column_name, data_type, character_maximum_length, numeric_precision, numeric_scale,
+	is_nullable, column_default, is_identity FROM information_schema.columns
+	WHERE table_schema=current_schema() AND table_name=$1 ORDER BY ordinal_position`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		col := &column{}
+		var charLen, precision, scale sql.NullInt64
+		var nullable, identity string
+		var def sql.NullString
+		err := rows.Scan(&col.Name, &col.Type, &charLen, &precision, &scale, &nullable, &def, &identity)
+		if err != nil {
+			return err
+		}
+
+		col.Type = strings.ToLower(col.Type)
+		switch {
+		case charLen.Valid:
+			col.Len = []int{int(charLen.Int64)}
+		case col.Type == "numeric" && precision.Valid && scale.Valid:
+			col.Len = []int{int(precision.Int64), int(scale.Int64)}
+		}
+
+		col.Nullable = nullable == "YES"
+		col.AI = identity == "YES" || strings.HasPrefix(def.String, "nextval(")
+		col.HasDefault = def.Valid && !col.AI
+		col.Default = def.String
+
+		t.Columns = append(t.Columns, col)
+	}
+
+	return rows.Err()
+}
+
+func (postgres) constraints(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT c.conname, c.contype, a.attname, COALESCE(rt.relname, ''), COALESCE(ra.attname, ''),
+	c.confupdtype, c.confdeltype, pg_get_constraintdef(c.oid)
+	FROM pg_constraint c
+	JOIN pg_class t ON t.oid=c.conrelid
+	CROSS JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, n)
+	JOIN pg_attribute a ON a.attrelid=c.conrelid AND a.attnum=k.attnum
+	LEFT JOIN pg_class rt ON rt.oid=c.confrelid
+	LEFT JOIN pg_attribute ra ON ra.attrelid=c.confrelid AND ra.attnum=c.confkey[k.n]
+	WHERE t.relname=$1 AND t.relnamespace=current_schema()::regnamespace
+	ORDER BY c.conname, k.n`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	composite := map[string]bool{}
+	for rows.Next() {
+		var name, typ, col, refTable, refCol, update, del, def string
+		err := rows.Scan(&name, &typ, &col, &refTable, &refCol, &update, &del, &def)
+		if err != nil {
+			return err
+		}
+
+		switch typ {
+		case "p":
+			t.PK = append(t.PK, col)
+		case "u":
+			t.Uniques[name] = append(t.Uniques[name], col)
+		case "f":
+			if _, found := t.FKs[name]; found {
+				composite[name] = true
+			}
+			t.FKs[name] = &foreignKey{
+				Column:     col,
+				RefTable:   refTable,
+				RefColumn:  refCol,
+				UpdateRule: postgresRules[update],
+				DeleteRule: postgresRules[del],
+			}
+		case "c": // CHECK ((id > 0))
+			if expr, ok := enclosed(strings.TrimPrefix(def, "CHECK (")); ok {
+				t.Checks[name] = expr
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for name := range composite {
+		t.Ignored = append(t.Ignored, fmt.Sprintf("外键 %s 包含多个列", name))
+		delete(t.FKs, name)
+	}
+
+	return nil
+}
+
+// 不属于任何约束的索引
+func (postgres) indexes(db *sql.DB, t *table) error {
+	rows, err := db.Query(`SELECT i.relname, ix.indisunique, a.attname
+	FROM pg_index ix
+	JOIN pg_class t ON t.oid=ix.indrelid
+	JOIN pg_class i ON i.oid=ix.indexrelid
+	CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, n)
+	JOIN pg_attribute a ON a.attrelid=t.oid AND a.attnum=k.attnum
+	WHERE t.relname=$1 AND t.relnamespace=current_schema()::regnamespace
+	AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conrelid=ix.indrelid AND c.conindid=ix.indexrelid)
+	ORDER BY i.relname, k.n`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, col string
+		var unique bool
+		if err := rows.Scan(&name, &unique, &col); err != nil {
+			return err
+		}
+
+		if unique {
+			t.Uniques[name] = append(t.Uniques[name], col)
+		} else {
+			t.Indexes[name] = append(t.Indexes[name], col)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,490 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 从数据库中读取表结构
+type inspector interface {
+	// 返回当前数据库中所有表的名称
+	tables(db *sql.DB) ([]string, error)
+
+	// 返回表 name 的结构
+	table(db *sql.DB, name string) (*table, error)
+}
+
+// 从数据库中读取的表结构
+type table struct {
+	Name    string
+	Columns []*column              // 按列在表中的顺序排列
+	PK      []string               // 主键中的列
+	Uniques map[string][]string    // 唯一约束，键名为约束名
+	Indexes map[string][]string    // 普通索引，键名为索引名
+	FKs     map[string]*foreignKey // 外键，键名为约束名
+	Checks  map[string]string      // check 约束，键名为约束名
+	Ignored []string               // 读取表结构时被忽略的内容
+}
+
+type column struct {
+	Name       string
+	Type       string // 数据库中的类型名称，小写且不包含长度
+	Len        []int  // 类型中的长度，未指定长度的为空
+	Nullable   bool
+	HasDefault bool
+	Default    string // 数据库返回的默认值表达式
+	AI         bool
+}
+
+type foreignKey struct {
+	Column     string
+	RefTable   string
+	RefColumn  string
+	UpdateRule string
+	DeleteRule string
+}
+
+// 用于生成代码的结构体
+type model struct {
+	Name    string // 结构体名称
+	Table   string // 表名，已经去掉了前缀
+	Meta    string
+	Fields  []*field
+	Ignored []string
+}
+
+type field struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// 各类数据库中的类型对应的 Go 类型，第二个元素为可以为 NULL 时使用的类型。
+var goTypes = map[string][2]string{}
+
+func init() {
+	register := func(types [2]string, names ...string) {
+		for _, name := range names {
+			goTypes[name] = types
+		}
+	}
+
+	register([2]string{"bool", "sql.NullBool"}, "bool", "boolean")
+	register([2]string{"int64", "sql.NullInt64"},
+		"int", "integer", "tinyint", "smallint", "mediumint", "bigint",
+		"int2", "int4", "int8", "serial", "smallserial", "bigserial")
+	register([2]string{"float64", "sql.NullFloat64"},
+		"real", "float", "double", "double precision", "float4", "float8", "numeric", "decimal")
+	register([2]string{"string", "sql.NullString"},
+		"char", "varchar", "character", "character varying", "nchar", "nvarchar",
+		"text", "tinytext", "mediumtext", "longtext", "clob", "json", "jsonb", "uuid", "enum", "set")
+	register([2]string{"[]byte", "[]byte"},
+		"blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "bytea")
+	register([2]string{"time.Time", "time.Time"},
+		"date", "datetime", "time", "timestamp",
+		"time without time zone", "time with time zone",
+		"timestamp without time zone", "timestamp with time zone")
+}
+
+// 这些单词在生成的名称中全部大写
+var initialisms = map[string]bool{
+	"id":   true,
+	"uid":  true,
+	"ip":   true,
+	"url":  true,
+	"uri":  true,
+	"api":  true,
+	"uuid": true,
+	"json": true,
+	"xml":  true,
+	"html": true,
+	"http": true,
+	"sql":  true,
+}
+
+// 转义单引号包含的 struct tag 参数中的特殊字符
+var argReplacer = strings.NewReplacer(`\`, `\\`, "'", `\'`)
+
+type generator struct {
+	prefix  string
+	imports map[string]bool
+	names   map[string]int // 已经使用的结构体名称
+}
+
+func newGenerator(prefix string) *generator {
+	return &generator{
+		prefix:  prefix,
+		imports: map[string]bool{},
+		names:   map[string]int{},
+	}
+}
+
+// 返回生成的代码需要导入的包，按路径排序。
+func (g *generator) importPaths() []string {
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (g *generator) model(t *table) *model {
+	m := &model{
+		Name:    uniqueName(g.names, exportedName(g.tableName(t.Name))),
+		Table:   g.tableName(t.Name),
+		Fields:  make([]*field, 0, len(t.Columns)),
+		Ignored: append([]string{}, t.Ignored...),
+	}
+
+	fields := map[string]int{}
+	for _, col := range t.Columns {
+		m.Fields = append(m.Fields, &field{
+			Name: uniqueName(fields, exportedName(col.Name)),
+			Type: g.goType(col),
+			Tag:  g.tag(t, col, m),
+		})
+	}
+
+	meta := []string{"name(" + m.Table + ")"}
+	for _, name := range sortedKeys(t.Checks) {
+		expr := trimParens(t.Checks[name])
+		chkName, ok1 := tagArg(name)
+		chkExpr, ok2 := tagArg(expr)
+		if !ok1 || !ok2 {
+			m.Ignored = append(m.Ignored, fmt.Sprintf("check 约束 %s: %s", name, expr))
+			continue
+		}
+		meta = append(meta, "check("+chkName+","+chkExpr+")")
+	}
+	m.Meta = strings.Join(meta, ";")
+
+	return m
+}
+
+func (g *generator) tableName(name string) string {
+	return strings.TrimPrefix(name, g.prefix)
+}
+
+func (g *generator) goType(col *column) string {
+	types, found := goTypes[col.Type]
+	if !found {
+		types = goTypes["text"]
+	}
+
+	typ := types[0]
+	if col.Nullable && !col.AI {
+		typ = types[1]
+	}
+
+	switch {
+	case strings.HasPrefix(typ, "sql."):
+		g.imports["database/sql"] = true
+	case strings.HasPrefix(typ, "time."):
+		g.imports["time"] = true
+	}
+
+	return typ
+}
+
+func (g *generator) tag(t *table, col *column, m *model) string {
+	tags := []string{"name(" + col.Name + ")"}
+
+	if l := length(col); l != "" {
+		tags = append(tags, "len("+l+")")
+	}
+
+	var pk bool
+	for _, name := range t.PK {
+		if name == col.Name {
+			pk = true
+			break
+		}
+	}
+
+	switch {
+	case col.AI:
+		tags = append(tags, "ai")
+	case pk:
+		tags = append(tags, "pk")
+	case col.Nullable:
+		tags = append(tags, "nullable")
+	}
+
+	if col.HasDefault && !col.AI && !(pk && len(t.PK) == 1) {
+		if val, ok := literal(col.Default); !ok {
+			m.Ignored = append(m.Ignored, fmt.Sprintf("列 %s 的默认值: %s", col.Name, col.Default))
+		} else if val, ok = tagArg(val); ok {
+			tags = append(tags, "default("+val+")")
+		} else {
+			m.Ignored = append(m.Ignored, fmt.Sprintf("列 %s 的默认值: %s", col.Name, col.Default))
+		}
+	}
+
+	for _, name := range sortedKeys(t.Uniques) {
+		if contains(t.Uniques[name], col.Name) {
+			tags = append(tags, "unique("+name+")")
+		}
+	}
+
+	for _, name := range sortedKeys(t.Indexes) {
+		if contains(t.Indexes[name], col.Name) {
+			tags = append(tags, "index("+name+")")
+		}
+	}
+
+	for _, name := range sortedKeys(t.FKs) {
+		if fk := t.FKs[name]; fk.Column == col.Name {
+			tags = append(tags, "fk("+g.fk(name, fk)+")")
+		}
+	}
+
+	return strings.Join(tags, ";")
+}
+
+// fk(fk_name,#refTable,refColName,updateRule,deleteRule)
+//
+// 与数据库默认值相同的 NO ACTION 会被省略。
+func (g *generator) fk(name string, fk *foreignKey) string {
+	args := []string{name, "#" + g.tableName(fk.RefTable), fk.RefColumn}
+
+	update := strings.ToUpper(fk.UpdateRule)
+	del := strings.ToUpper(fk.DeleteRule)
+	if del != "" && del != "NO ACTION" {
+		args = append(args, update, del)
+	} else if update != "" && update != "NO ACTION" {
+		args = append(args, update)
+	}
+
+	return strings.Join(args, ",")
+}
+
+// 返回 len() 中的内容，字符串类型未指定长度的，返回 -1。
+func length(col *column) string {
+	types, found := goTypes[col.Type]
+	if !found {
+		types = goTypes["text"]
+	}
+
+	switch types[0] {
+	case "string":
+		if len(col.Len) == 0 || col.Len[0] <= 0 || col.Len[0] > 65533 {
+			return "-1"
+		}
+		return strconv.Itoa(col.Len[0])
+	case "float64":
+		if len(col.Len) == 2 && col.Len[0] > 0 {
+			return strconv.Itoa(col.Len[0]) + "," + strconv.Itoa(col.Len[1])
+		}
+	}
+
+	return ""
+}
+
+// 将 name 转换成可导出的 Go 名称，比如 user_info 转换成 UserInfo。
+func exportedName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	buf := new(strings.Builder)
+	for _, part := range parts {
+		if initialisms[strings.ToLower(part)] {
+			buf.WriteString(strings.ToUpper(part))
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(part)
+		buf.WriteRune(unicode.ToUpper(r))
+		buf.WriteString(part[size:])
+	}
+
+	ret := buf.String()
+	if r, _ := utf8.DecodeRuneInString(ret); !unicode.IsUpper(r) {
+		ret = "X" + ret
+	}
+	return ret
+}
+
+// 若 name 已经在 names 中，则在其后加上数字以区分。
+func uniqueName(names map[string]int, name string) string {
+	n := names[name]
+	names[name] = n + 1
+	if n == 0 {
+		return name
+	}
+
+	return uniqueName(names, name+strconv.Itoa(n+1))
+}
+
+// 将数据库返回的默认值表达式转换成 default() 中使用的值，
+// 仅支持字符串和数值的字面量，比如 'male'、'male'::character varying 和 5。
+func literal(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+
+	if !strings.HasPrefix(expr, "'") {
+		if _, err := strconv.ParseFloat(expr, 64); err != nil {
+			return "", false
+		}
+		return expr, true
+	}
+
+	buf := new(strings.Builder)
+	for i := 1; i < len(expr); i++ {
+		if expr[i] != '\'' {
+			buf.WriteByte(expr[i])
+			continue
+		}
+
+		if i+1 < len(expr) && expr[i+1] == '\'' { // 转义的单引号
+			buf.WriteByte('\'')
+			i++
+			continue
+		}
+
+		rest := expr[i+1:]
+		if rest != "" && !strings.HasPrefix(rest, "::") {
+			return "", false
+		}
+		return buf.String(), true
+	}
+
+	return "", false
+}
+
+// 去掉表达式最外层多余的括号，比如 ((id > 0)) 返回 id > 0。
+func trimParens(expr string) string {
+	for {
+		expr = strings.TrimSpace(expr)
+		if len(expr) < 2 || expr[0] != '(' || expr[len(expr)-1] != ')' {
+			return expr
+		}
+
+		depth := 0
+		for i := 0; i < len(expr)-1; i++ {
+			switch expr[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+
+			if depth == 0 { // 第一个括号并不是与最后一个括号配对
+				return expr
+			}
+		}
+
+		expr = expr[1 : len(expr)-1]
+	}
+}
+
+// 将 val 转换成 struct tag 中的参数，
+// 包含分隔符等特殊字符时，会以单引号包含并转义其中的单引号和反斜杠。
+//
+// 生成的 struct tag 位于以反引号包含的字符串中，所以无法包含反引号。
+func tagArg(val string) (string, bool) {
+	if strings.ContainsRune(val, '`') {
+		return "", false
+	}
+
+	if val != "" && strings.TrimSpace(val) == val && !strings.ContainsAny(val, "();,'\"\\") {
+		return val, true
+	}
+
+	return "'" + argReplacer.Replace(val) + "'", true
+}
+
+func contains(cols []string, name string) bool {
+	for _, col := range cols {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string][]string:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*foreignKey:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]string:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func newTable(name string) *table {
+	return &table{
+		Name:    name,
+		Uniques: map[string][]string{},
+		Indexes: map[string][]string{},
+		FKs:     map[string]*foreignKey{},
+		Checks:  map[string]string{},
+	}
+}
+
+// 将 VARCHAR(20)、DECIMAL(10,2) 等类型拆分成类型名称和长度。
+func parseType(typ string) (string, []int) {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+
+	start := strings.IndexByte(typ, '(')
+	if start < 0 {
+		return strings.TrimSuffix(typ, " unsigned"), nil
+	}
+
+	end := strings.IndexByte(typ, ')')
+	if end < start {
+		return strings.TrimSpace(typ[:start]), nil
+	}
+
+	var lens []int
+	for _, s := range strings.Split(typ[start+1:end], ",") {
+		l, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return strings.TrimSpace(typ[:start]), nil
+		}
+		lens = append(lens, l)
+	}
+
+	return strings.TrimSpace(typ[:start]), lens
+}
+
+// 执行查询语句，返回第一列的所有值。
+func queryStrings(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+
+	return ret, rows.Err()
+}
@@ -0,0 +1,242 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sqlite3 的 PRAGMA 并不会返回约束的名称，需要从建表语句中分析。
+var (
+	sqlite3Ident      = "[`\"\\[]?(\\w+)[`\"\\]]?"
+	sqlite3UniqueExpr = regexp.MustCompile(`(?i)CONSTRAINT\s+` + sqlite3Ident + `\s+UNIQUE\s*\(([^)]*)\)`)
+	sqlite3FKExpr     = regexp.MustCompile(`(?i)CONSTRAINT\s+` + sqlite3Ident + `\s+FOREIGN\s+KEY\s*\(([^)]*)\)`)
+	sqlite3CheckExpr  = regexp.MustCompile(`(?i)CONSTRAINT\s+` + sqlite3Ident + `\s+CHECK\s*\(`)
+	sqlite3AIExpr     = regexp.MustCompile(`(?i)\bAUTOINCREMENT\b`)
+)
+
+type sqlite3 struct{}
+
+type sqlite3Index struct {
+	name   string
+	unique bool
+	origin string // c 表示通过 CREATE INDEX 创建；u 表示 UNIQUE 约束；pk 表示主键
+}
+
+func (sqlite3) tables(db *sql.DB) ([]string, error) {
+	return queryStrings(db, "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+}
+
+func (s sqlite3) table(db *sql.DB, name string) (*table, error) {
+	var ddl string
+	err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type='table' AND name=?", name).Scan(&ddl)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newTable(name)
+	if err = s.columns(db, t, ddl); err != nil {
+		return nil, err
+	}
+
+	if err = s.indexes(db, t, ddl); err != nil {
+		return nil, err
+	}
+
+	if err = s.foreignKeys(db, t, ddl); err != nil {
+		return nil, err
+	}
+
+	for _, loc := range sqlite3CheckExpr.FindAllStringSubmatchIndex(ddl, -1) {
+		chkName := ddl[loc[2]:loc[3]]
+		if expr, ok := enclosed(ddl[loc[1]:]); ok {
+			t.Checks[chkName] = expr
+		}
+	}
+
+	return t, nil
+}
+
+func (sqlite3) columns(db *sql.DB, t *table, ddl string) error {
+	rows, err := db.Query(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	pks := map[int]string{}
+	for rows.Next() {
+		col := &column{}
+		var typ string
+		var notnull bool
+		var def sql.NullString
+		var pk int
+		if err := rows.Scan(&col.Name, &typ, &notnull, &def, &pk); err != nil {
+			return err
+		}
+
+		col.Type, col.Len = parseType(typ)
+		col.Nullable = !notnull && pk == 0
+		col.HasDefault = def.Valid
+		col.Default = def.String
+		if pk > 0 {
+			pks[pk] = col.Name
+		}
+
+		t.Columns = append(t.Columns, col)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := 1; i <= len(pks); i++ {
+		t.PK = append(t.PK, pks[i])
+	}
+
+	// 只有 INTEGER PRIMARY KEY 才能使用 AUTOINCREMENT
+	if len(t.PK) == 1 && sqlite3AIExpr.MatchString(ddl) {
+		for _, col := range t.Columns {
+			if col.Name == t.PK[0] && col.Type == "integer" {
+				col.AI = true
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s sqlite3) indexes(db *sql.DB, t *table, ddl string) error {
+	rows, err := db.Query(`SELECT name, "unique", origin FROM pragma_index_list(?)`, t.Name)
+	if err != nil {
+		return err
+	}
+
+	var indexes []*sqlite3Index
+	for rows.Next() {
+		index := &sqlite3Index{}
+		if err := rows.Scan(&index.name, &index.unique, &index.origin); err != nil {
+			rows.Close()
+			return err
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// 约束名与列的对应关系
+	named := map[string]string{}
+	for _, match := range sqlite3UniqueExpr.FindAllStringSubmatch(ddl, -1) {
+		named[columnList(match[2])] = match[1]
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].name < indexes[j].name })
+	for i, index := range indexes {
+		if index.origin == "pk" {
+			continue
+		}
+
+		cols, err := queryStrings(db, "SELECT name FROM pragma_index_info(?) ORDER BY seqno", index.name)
+		if err != nil {
+			return err
+		}
+
+		indexName := index.name
+		if index.origin == "u" {
+			var found bool
+			if indexName, found = named[strings.Join(cols, ",")]; !found {
+				indexName = fmt.Sprintf("unique_%s_%d", t.Name, i+1)
+			}
+		}
+
+		if index.unique {
+			t.Uniques[indexName] = cols
+		} else {
+			t.Indexes[indexName] = cols
+		}
+	}
+
+	return nil
+}
+
+func (sqlite3) foreignKeys(db *sql.DB, t *table, ddl string) error {
+	rows, err := db.Query(`SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, t.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	named := map[string]string{}
+	for _, match := range sqlite3FKExpr.FindAllStringSubmatch(ddl, -1) {
+		named[columnList(match[2])] = match[1]
+	}
+
+	fks := map[int]*foreignKey{}
+	var composite []int
+	for rows.Next() {
+		var id int
+		fk := &foreignKey{}
+		if err := rows.Scan(&id, &fk.RefTable, &fk.Column, &fk.RefColumn, &fk.UpdateRule, &fk.DeleteRule); err != nil {
+			return err
+		}
+
+		if _, found := fks[id]; found {
+			composite = append(composite, id)
+			continue
+		}
+		fks[id] = fk
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range composite {
+		if fk, found := fks[id]; found {
+			t.Ignored = append(t.Ignored, fmt.Sprintf("外键 %s 包含多个列", fk.Column))
+			delete(fks, id)
+		}
+	}
+
+	for id, fk := range fks {
+		name, found := named[fk.Column]
+		if !found {
+			name = fmt.Sprintf("fk_%s_%d", t.Name, id+1)
+		}
+		t.FKs[name] = fk
+	}
+
+	return nil
+}
+
+// 将 `a`, "b" 这样的列列表转换成 a,b
+func columnList(list string) string {
+	cols := strings.Split(list, ",")
+	for i, col := range cols {
+		cols[i] = strings.Trim(strings.TrimSpace(col), "`\"[]")
+	}
+	return strings.Join(cols, ",")
+}
+
+// s 为左括号之后的内容，返回与该左括号配对的右括号之前的内容。
+func enclosed(s string) (string, bool) {
+	depth := 1
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return strings.TrimSpace(s[:i]), true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// ormschema 将模型的表结构导出为 SQL 脚本。
+//
+// 由于 Go 无法在运行时加载其它包中的类型，ormschema 会在当前目录下
+// 生成一个导入了模型所在包的临时程序，并通过 go run 执行，
+// 所以需要在模型所在的 module 中执行：
+//  ormschema -pkg github.com/example/app/models -types User,Group -dialect mysql -prefix p_ -o schema.sql
+//
+// 具体的脚本内容可参考 orm.WriteSchema()。
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var dialects = map[string]string{
+	"mysql":    "Mysql",
+	"postgres": "Postgres",
+	"sqlite3":  "Sqlite3",
+}
+
+var tpl = template.Must(template.New("main").Parse(`// 由 ormschema 生成，执行完之后会被删除。
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+
+	models {{printf "%q" .Pkg}}
+)
+
+func main() {
+	err := orm.WriteSchema(os.Stdout, dialect.{{.Dialect}}(), {{printf "%q" .Prefix}},
+		{{- range .Types}}
+		&models.{{.}}{},
+		{{- end}}
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+type options struct {
+	Pkg     string
+	Types   []string
+	Dialect string
+	Prefix  string
+}
+
+func main() {
+	pkg := flag.String("pkg", "", "模型所在包的导入路径")
+	types := flag.String("types", "", "需要导出的模型类型名称，多个以逗号分隔")
+	d := flag.String("dialect", "mysql", "数据库类型，可以是 mysql、postgres 和 sqlite3")
+	prefix := flag.String("prefix", "", "表名前缀")
+	output := flag.String("o", "", "输出的文件，为空表示输出到终端")
+	flag.Parse()
+
+	opt, err := newOptions(*pkg, *types, *d, *prefix)
+	if err != nil {
+		exit(err)
+	}
+
+	src, err := run(opt)
+	if err != nil {
+		exit(err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0644)
+	}
+	if err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+func newOptions(pkg, types, d, prefix string) (*options, error) {
+	if pkg == "" {
+		return nil, errors.New("未指定 -pkg 参数")
+	}
+
+	opt := &options{Pkg: pkg, Prefix: prefix}
+
+	for _, typ := range strings.Split(types, ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			opt.Types = append(opt.Types, typ)
+		}
+	}
+	if len(opt.Types) == 0 {
+		return nil, errors.New("未指定 -types 参数")
+	}
+
+	var found bool
+	if opt.Dialect, found = dialects[d]; !found {
+		return nil, fmt.Errorf("不支持的数据库类型 %s", d)
+	}
+
+	return opt, nil
+}
+
+// 生成临时程序的源码
+func generate(opt *options) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := tpl.Execute(buf, opt); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// 在当前目录下生成临时程序并执行，返回其输出的内容。
+//
+// 只有在执行成功之后才写入输出文件，以免失败时覆盖原有的内容。
+func run(opt *options) ([]byte, error) {
+	src, err := generate(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(".", "ormschema")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), src, 0644); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
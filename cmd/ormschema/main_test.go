@@ -0,0 +1,40 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+func TestNewOptions(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions("github.com/issue9/orm/internal/modeltest", "Group, Admin,", "sqlite3", "p_")
+	a.NotError(err).NotNil(opt)
+	a.Equal(opt.Types, []string{"Group", "Admin"}).Equal(opt.Dialect, "Sqlite3")
+
+	_, err = newOptions("", "Group", "mysql", "")
+	a.Error(err)
+	_, err = newOptions("github.com/issue9/orm/internal/modeltest", " ", "mysql", "")
+	a.Error(err)
+	_, err = newOptions("github.com/issue9/orm/internal/modeltest", "Group", "oracle", "")
+	a.Error(err)
+}
+
+func TestGenerate(t *testing.T) {
+	a := assert.New(t)
+
+	opt, err := newOptions("github.com/issue9/orm/internal/modeltest", "Group,Admin", "mysql", "p_")
+	a.NotError(err)
+
+	src, err := generate(opt)
+	a.NotError(err)
+	a.Contains(string(src), `models "github.com/issue9/orm/internal/modeltest"`)
+	a.Contains(string(src), `dialect.Mysql(), "p_"`)
+	a.Contains(string(src), "&models.Group{},")
+	a.Contains(string(src), "&models.Admin{},")
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// StmtKind 语句的类型，用于 Collector 分类统计。
+type StmtKind string
+
+// StmtKind 的各类值
+const (
+	StmtSelect StmtKind = "select"
+	StmtInsert StmtKind = "insert"
+	StmtUpdate StmtKind = "update"
+	StmtDelete StmtKind = "delete"
+	StmtDDL    StmtKind = "ddl"
+	StmtOther  StmtKind = "other"
+)
+
+// Collector 收集数据库操作的统计数据。
+//
+// 各个方法可能会被并发调用，实现者需要自行保证并发安全。
+// github.com/issue9/orm/metrics 中提供了一个基于内存的实现。
+type Collector interface {
+	// 每条语句执行之后调用。
+	//
+	// 通过 Prepare() 预编译的语句不会被统计；
+	// QueryRow() 的错误在 sql.Row.Scan() 时才返回，所以总是被当作执行成功。
+	//
+	// class 为错误的分类，由 ErrorClass() 生成，执行成功时为空。
+	Statement(kind StmtKind, dur time.Duration, class string)
+
+	// 事务开始时调用
+	TxBegin()
+
+	// 事务结束时调用，committed 表示事务是否被成功提交。
+	TxEnd(committed bool)
+
+	// 在 DB.CollectStats() 以及健康检测时调用，stats 为主数据库连接池的状态。
+	DBStats(stats sql.DBStats)
+}
+
+// WithCollector 返回一个将统计数据发送给 c 的 DB 实例。
+//
+// 返回的实例与当前实例共用连接池，c 为 nil 表示不统计。
+// 也可以通过 Options.Collector 在初始化时指定。
+func (db *DB) WithCollector(c Collector) *DB {
+	inst := *db
+	inst.collector = c
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// CollectStats 将当前连接池的状态发送给 Collector
+//
+// 适用于在采集统计数据之前主动更新连接池的状态，未指定 Collector 时不执行任何操作。
+func (db *DB) CollectStats() {
+	if db.collector != nil {
+		db.collector.DBStats(db.Stats())
+	}
+}
+
+// 对执行语句返回的错误作转换，并记录统计数据。
+func (db *DB) finish(query string, start time.Time, err error) error {
+	if err != nil {
+		err = db.dialect.TranslateError(err)
+	}
+
+	if db.collector != nil {
+		db.collector.Statement(stmtKind(query), time.Since(start), ErrorClass(db.dialect, err))
+	}
+
+	return err
+}
+
+// ErrorClass 返回 err 的分类，可用于统计或是日志。
+//
+// 返回值为以下值之一：
+//  unique_violation, foreign_key_violation, not_null_violation, check_violation,
+//  no_rows, optimistic_lock, timeout, canceled, retryable, other
+// err 为 nil 时返回空字符串。
+func ErrorClass(d Dialect, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrUniqueViolation):
+		return "unique_violation"
+	case errors.Is(err, ErrForeignKeyViolation):
+		return "foreign_key_violation"
+	case errors.Is(err, ErrNotNullViolation):
+		return "not_null_violation"
+	case errors.Is(err, ErrCheckViolation):
+		return "check_violation"
+	case errors.Is(err, ErrNoRows):
+		return "no_rows"
+	case errors.Is(err, ErrOptimisticLock):
+		return "optimistic_lock"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case d != nil && d.Retryable(err):
+		return "retryable"
+	default:
+		return "other"
+	}
+}
+
+// 根据语句的第一个关键字判断语句的类型
+func stmtKind(query string) StmtKind {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	if i := strings.IndexAny(query, " \t\r\n("); i > 0 {
+		query = query[:i]
+	}
+
+	switch strings.ToUpper(query) {
+	case "SELECT", "WITH":
+		return StmtSelect
+	case "INSERT", "REPLACE":
+		return StmtInsert
+	case "UPDATE":
+		return StmtUpdate
+	case "DELETE":
+		return StmtDelete
+	case "CREATE", "DROP", "ALTER", "TRUNCATE":
+		return StmtDDL
+	default:
+		return StmtOther
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/issue9/assert"
+)
+
+func TestStmtKind(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(stmtKind("SELECT * FROM tbl"), StmtSelect)
+	a.Equal(stmtKind(" (select 1) UNION (select 2)"), StmtSelect)
+	a.Equal(stmtKind("with t AS(SELECT 1) SELECT * FROM t"), StmtSelect)
+	a.Equal(stmtKind("INSERT INTO tbl VALUES(?)"), StmtInsert)
+	a.Equal(stmtKind("\n\tupdate tbl SET a=1"), StmtUpdate)
+	a.Equal(stmtKind("DELETE FROM tbl"), StmtDelete)
+	a.Equal(stmtKind("CREATE TABLE tbl(id INT)"), StmtDDL)
+	a.Equal(stmtKind("TRUNCATE tbl"), StmtDDL)
+	a.Equal(stmtKind("PRAGMA foreign_keys=ON"), StmtOther)
+	a.Equal(stmtKind(""), StmtOther)
+}
+
+func TestErrorClass(t *testing.T) {
+	a := assert.New(t)
+
+	a.Empty(ErrorClass(nil, nil))
+	a.Equal(ErrorClass(nil, &ConstraintError{Err: ErrUniqueViolation}), "unique_violation")
+	a.Equal(ErrorClass(nil, fmt.Errorf("wrap: %w", ErrNoRows)), "no_rows")
+	a.Equal(ErrorClass(nil, &NotFoundError{Indexes: []int{1}}), "no_rows")
+	a.Equal(ErrorClass(nil, ErrOptimisticLock), "optimistic_lock")
+	a.Equal(ErrorClass(nil, context.DeadlineExceeded), "timeout")
+	a.Equal(ErrorClass(nil, errors.New("abc")), "other")
+}
@@ -5,6 +5,7 @@
 package orm
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
 )
@@ -48,12 +49,17 @@ func (c *Column) IsZero(v reflect.Value) bool {
 		return false
 	}
 
+	return c.isZeroValue(v.Interface())
+}
+
+// 判断列的值 v 是否为零值，v 的类型必须为 GoType。
+func (c *Column) isZeroValue(v interface{}) bool {
 	if c.GoType.Comparable() {
-		return c.zero == v.Interface()
+		return c.zero == v
 	}
 
-	if v.Kind() == reflect.Slice {
-		return v.Len() == 0
+	if c.GoType.Kind() == reflect.Slice {
+		return reflect.ValueOf(v).Len() == 0
 	}
 
 	return false
@@ -78,7 +84,7 @@ func (c *Column) setLen(vals []string) (err error) {
 
 		c.Len2, err = strconv.Atoi(vals[1])
 	default:
-		return propertyError(c.Name, "len", "过多的参数")
+		return errors.New("过多的参数")
 	}
 
 	return
@@ -88,7 +94,7 @@ func (c *Column) setLen(vals []string) (err error) {
 // nullable; or nullable(true);
 func (c *Column) setNullable(vals []string) (err error) {
 	if c.IsAI() {
-		return propertyError(c.Name, "nullable", "自增列不能设置此值")
+		return errors.New("自增列不能设置此值")
 	}
 
 	switch len(vals) {
@@ -99,7 +105,7 @@ func (c *Column) setNullable(vals []string) (err error) {
 			return err
 		}
 	default:
-		return propertyError(c.Name, "nullable", "过多的参数值")
+		return errors.New("过多的参数值")
 	}
 
 	return nil
@@ -8,6 +8,7 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"time"
 )
 
 // DB 数据库操作实例。
@@ -17,6 +18,20 @@ type DB struct {
 	tablePrefix string
 	replacer    *strings.Replacer
 	sql         *SQL
+
+	replicas *replicas // 从数据库，为 nil 表示没有
+	primary  bool      // 只读操作也在主数据库中执行
+
+	tenant interface{} // 限定的租户，为 nil 表示不限定
+
+	ignoreNotFound bool // Select 未找到数据时不返回错误
+	checkAffected  bool // Delete 未删除任何数据时返回错误
+
+	health    *healthChecker // 健康检测，为 nil 表示未启用
+	collector Collector      // 统计数据的收集，为 nil 表示不收集
+
+	tracer Tracer          // 追踪，为 nil 表示不追踪
+	ctx    context.Context // 不带 context 参数的方法所使用的 context，为 nil 表示 context.Background()
 }
 
 // NewDB 声明一个新的 DB 实例。
@@ -31,16 +46,11 @@ func NewDB(driverName, dataSourceName, tablePrefix string, dialect Dialect) (*DB
 
 // NewDBWithStdDB 从 sql.DB 构建一个 DB 实例。
 func NewDBWithStdDB(db *sql.DB, tablePrefix string, dialect Dialect) (*DB, error) {
-	l, r := dialect.QuoteTuple()
 	inst := &DB{
 		stdDB:       db,
 		dialect:     dialect,
 		tablePrefix: tablePrefix,
-		replacer: strings.NewReplacer(
-			"#", tablePrefix,
-			"{", string(l),
-			"}", string(r),
-		),
+		replacer:    newReplacer(tablePrefix, dialect),
 	}
 
 	inst.sql = &SQL{engine: inst}
@@ -48,15 +58,87 @@ func NewDBWithStdDB(db *sql.DB, tablePrefix string, dialect Dialect) (*DB, error
 	return inst, nil
 }
 
+func newReplacer(tablePrefix string, dialect Dialect) *strings.Replacer {
+	l, r := dialect.QuoteTuple()
+	return strings.NewReplacer(
+		"#", tablePrefix,
+		"{", string(l),
+		"}", string(r),
+	)
+}
+
+// WithPrefix 返回一个使用表名前缀 tablePrefix 的 DB 实例。
+//
+// 返回的实例与当前实例共用连接池及 Dialect，仅表名前缀不同，
+// 适用于同一个数据库中以不同表名前缀区分租户的情况。
+// 关闭返回的实例，当前实例也将失效。
+func (db *DB) WithPrefix(tablePrefix string) *DB {
+	inst := *db
+	inst.tablePrefix = tablePrefix
+	inst.replacer = newReplacer(tablePrefix, db.dialect)
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// TablePrefix 返回表名前缀
+func (db *DB) TablePrefix() string {
+	return db.tablePrefix
+}
+
+// IgnoreNotFound 返回一个在 Select 未找到数据时不返回 ErrNoRows 的 DB 实例。
+//
+// 用于兼容之前的行为：未找到数据时，返回 nil 且不对参数作任何改动。
+// 返回的实例与当前实例共用连接池。
+func (db *DB) IgnoreNotFound() *DB {
+	inst := *db
+	inst.ignoreNotFound = true
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// CheckAffected 返回一个在 Delete 未删除任何数据时返回 ErrNoRows 的 DB 实例。
+//
+// 默认情况下，未删除任何数据并不被当作错误。
+// 返回的实例与当前实例共用连接池。
+func (db *DB) CheckAffected() *DB {
+	inst := *db
+	inst.checkAffected = true
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// 获取 e 对应的 DB 实例，e 为 DB 或 Tx 之外的类型时返回 nil。
+func dbOf(e Engine) *DB {
+	switch e := e.(type) {
+	case *DB:
+		return e
+	case *Tx:
+		return e.db
+	default:
+		return nil
+	}
+}
+
 // Close 关闭当前数据库，释放所有的链接。
 //
 // 关闭之后，之前通过 DB.StdDB() 返回的实例也将失效。
 // 通过调用 DB.StdDB().Close() 也将使当前实例失效。
+// 若指定了从数据库，从数据库也会被一并关闭，健康检测也会一并停止。
 func (db *DB) Close() error {
-	return db.stdDB.Close()
+	if db.health != nil {
+		db.health.stop()
+	}
+
+	err := db.stdDB.Close()
+	if db.replicas != nil {
+		if e := db.replicas.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
 }
 
-// StdDB 返回标准包中的 sql.DB 指针。
+// StdDB 返回标准包中的 sql.DB 指针，即主数据库。
 func (db *DB) StdDB() *sql.DB {
 	return db.stdDB
 }
@@ -70,97 +152,115 @@ func (db *DB) Dialect() Dialect {
 //
 // 如果生成语句出错，则会 panic
 func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
-	if err != nil {
-		panic(err)
-	}
-
-	return db.stdDB.QueryRow(query, args...)
+	return db.QueryRowContext(db.context(), query, args...)
 }
 
 // QueryRowContext 执行一条查询语句，并返回相应的 sql.Rows 实例。
 //
-// 如果生成语句出错，则会 panic
+// 如果生成语句出错，则会 panic；
+// 执行过程中的错误由 sql.Row.Scan() 返回，不会经过 Dialect.TranslateError() 转换。
 func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+	query, args, err := db.prepareSQL(query, args)
 	if err != nil {
 		panic(err)
 	}
 
-	return db.stdDB.QueryRowContext(ctx, query, args...)
+	ctx, span := db.startStmtSpan(ctx, "orm.QueryRow", query)
+	start := time.Now()
+	row := db.reader().QueryRowContext(ctx, query, args...)
+	db.finish(query, start, nil)
+	endSpan(span, -1, nil)
+	return row
 }
 
 // Query 执行一条查询语句，并返回相应的 sql.Rows 实例。
 func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return db.stdDB.Query(query, args...)
+	return db.QueryContext(db.context(), query, args...)
 }
 
 // QueryContext 执行一条查询语句，并返回相应的 sql.Rows 实例。
 func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+	query, args, err := db.prepareSQL(query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return db.stdDB.QueryContext(ctx, query, args...)
-}
-
-// Exec 执行 SQL 语句。
-func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+	ctx, span := db.startStmtSpan(ctx, "orm.Query", query)
+	start := time.Now()
+	rows, err := db.reader().QueryContext(ctx, query, args...)
+	err = db.finish(query, start, err)
+	endSpan(span, -1, err)
 	if err != nil {
 		return nil, err
 	}
+	return rows, nil
+}
 
-	return db.stdDB.Exec(query, args...)
+// Exec 执行 SQL 语句。
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(db.context(), query, args...)
 }
 
 // ExecContext 执行 SQL 语句。
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+	query, args, err := db.prepareSQL(query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return db.stdDB.ExecContext(ctx, query, args...)
+	ctx, span := db.startStmtSpan(ctx, "orm.Exec", query)
+	start := time.Now()
+	r, err := db.stdDB.ExecContext(ctx, query, args...)
+	err = db.finish(query, start, err)
+	endSpan(span, rowsAffected(r), err)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 // Prepare 预编译查询语句。
+//
+// 预编译的语句不会对命名参数作转换。
 func (db *DB) Prepare(query string) (*sql.Stmt, error) {
-	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+	return db.PrepareContext(db.context(), query)
+}
+
+// PrepareContext 预编译查询语句。
+//
+// 预编译的语句不会对命名参数作转换，且总是在主数据库中执行。
+func (db *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	query, _, err := db.prepareSQL(query, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return db.stdDB.Prepare(query)
+	return db.stdDB.PrepareContext(ctx, query)
 }
 
-// PrepareContext 预编译查询语句。
-func (db *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+// 对 SQL 语句作执行前的处理：
+// 替换表名前缀和引号占位符，将命名参数转换成普通的占位符，
+// 最后再由 Dialect 作调整。
+func (db *DB) prepareSQL(query string, args []interface{}) (string, []interface{}, error) {
 	query = db.replacer.Replace(query)
-	query, err := db.dialect.SQL(query)
+
+	query, args, err := convertNamedArgs(query, args, db.dialect.BackslashEscapes())
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	return db.stdDB.PrepareContext(ctx, query)
+	query, err = db.dialect.SQL(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return query, args, nil
 }
 
 // LastInsertID 插入数据，并获取其自增的 ID。
 func (db *DB) LastInsertID(v interface{}) (int64, error) {
-	return lastInsertID(db, v)
+	// 部分数据库通过查询语句获取 ID，需要保证在主数据库中执行。
+	return lastInsertID(db.Primary(), v)
 }
 
 // Insert 插入数据，若需一次性插入多条数据，请使用 tx.Insert()。
@@ -168,10 +268,78 @@ func (db *DB) Insert(v interface{}) (sql.Result, error) {
 	return insert(db, v)
 }
 
+// InsertMany 插入多条相同类型的数据。
+//
+// 数据会根据 Dialect.MaxArgs() 拆分成多条语句，并在同一个事务中执行。
+// 具体可参考 Tx.InsertMany()。
+func (db *DB) InsertMany(v interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.InsertMany(v); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LastInsertIDs 插入多条相同类型的数据，并按顺序返回其自增 ID。
+//
+// 所有的插入操作都在同一个事务中执行，具体可参考 Tx.LastInsertIDs()。
+func (db *DB) LastInsertIDs(v interface{}) ([]int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := tx.LastInsertIDs(v)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BulkLoad 大批量导入数据，返回实际导入的数量。
+//
+// 所有数据在同一个事务中导入，具体可参考 Tx.BulkLoad()。
+func (db *DB) BulkLoad(v interface{}) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := tx.BulkLoad(v)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // Delete 删除符合条件的数据。
 //
 // 查找条件以结构体定义的主键或是唯一约束(在没有主键的情况下)来查找，
 // 若两者都不存在，则将返回 error
+//
+// 通过 CheckAffected() 返回的实例，可以在没有删除任何数据时返回 ErrNoRows。
 func (db *DB) Delete(v interface{}) (sql.Result, error) {
 	return del(db, v)
 }
@@ -180,15 +348,65 @@ func (db *DB) Delete(v interface{}) (sql.Result, error) {
 //
 // 查找条件以结构体定义的主键或是唯一约束(在没有主键的情况下)来查找，
 // 若两者都不存在，则将返回 error
+//
+// 若模型指定了乐观锁列，在未更新任何数据时返回 ErrOptimisticLock，
+// 更新成功之后，v 中乐观锁字段的值会加 1，以便再次更新。
 func (db *DB) Update(v interface{}, cols ...string) (sql.Result, error) {
 	return update(db, v, cols...)
 }
 
+// UpdateMany 以尽量少的语句同时更新多条相同类型的数据，返回受影响的记录数量。
+//
+// 所有语句在同一个事务中执行，具体可参考 Tx.UpdateMany()。
+func (db *DB) UpdateMany(v interface{}, cols ...string) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := tx.UpdateMany(v, cols...)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteMany 同时删除多条相同类型的数据，返回受影响的记录数量。
+//
+// 所有语句在同一个事务中执行，具体可参考 Tx.DeleteMany()。
+func (db *DB) DeleteMany(v interface{}) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := tx.DeleteMany(v)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, err
+		}
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // Select 查询一个符合条件的数据。
 //
 // 查找条件以结构体定义的主键或是唯一约束(在没有主键的情况下 ) 来查找，
 // 若两者都不存在，则将返回 error
-// 若没有符合条件的数据，将不会对参数v做任何变动。
+// 若没有符合条件的数据，将不会对参数v做任何变动，并返回 ErrNoRows，
+// 可以通过 IgnoreNotFound() 返回的实例取消该错误。
 func (db *DB) Select(v interface{}) error {
 	return find(db, v)
 }
@@ -325,14 +543,13 @@ func (db *DB) MultDelete(objs ...interface{}) error {
 }
 
 // MultCreate 创建数据表。
+//
+// 表按外键的依赖关系创建，被引用的表先于引用它的表创建。
+// 外键存在循环引用时，会先创建不包含这些外键的表，
+// 再通过 ALTER TABLE 添加外键。
 func (db *DB) MultCreate(objs ...interface{}) error {
 	if !db.Dialect().TransactionalDDL() {
-		for _, v := range objs {
-			if err := db.Create(v); err != nil {
-				return err
-			}
-		}
-		return nil
+		return multCreate(db, objs)
 	}
 
 	tx, err := db.Begin()
@@ -351,14 +568,11 @@ func (db *DB) MultCreate(objs ...interface{}) error {
 }
 
 // MultDrop 删除表结构及数据。
+//
+// 按与 MultCreate 相反的顺序删除。
 func (db *DB) MultDrop(objs ...interface{}) error {
 	if !db.Dialect().TransactionalDDL() {
-		for _, v := range objs {
-			if err := db.Drop(v); err != nil {
-				return err
-			}
-		}
-		return nil
+		return multDrop(db, objs)
 	}
 
 	tx, err := db.Begin()
@@ -377,6 +591,8 @@ func (db *DB) MultDrop(objs ...interface{}) error {
 }
 
 // MultTruncate 清除表内容，重置 ai，但保留表结构。
+//
+// 按与 MultCreate 相反的顺序清除。
 func (db *DB) MultTruncate(objs ...interface{}) error {
 	tx, err := db.Begin()
 	if err != nil {
@@ -5,6 +5,7 @@
 package orm_test
 
 import (
+	"database/sql"
 	"os"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/issue9/orm/dialect"
 	"github.com/issue9/orm/fetch"
 	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/ormtest"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
@@ -236,6 +238,119 @@ func TestDB_Delete(t *testing.T) {
 	a.Equal(a1.ID, 2) // a1.ID为一个自增列,不会在delete中被重置
 }
 
+func TestDB_Delete_notFound(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	// 默认情况下，未删除数据并不是错误
+	r, err := db.Delete(&modeltest.UserInfo{UID: 100})
+	a.NotError(err)
+	cnt, err := r.RowsAffected()
+	a.NotError(err).Equal(cnt, 0)
+	a.NotError(db.MultDelete(&modeltest.UserInfo{UID: 1}, &modeltest.UserInfo{UID: 100}))
+	hasCount(db, a, "user_info", 1)
+
+	_, err = db.CheckAffected().Delete(&modeltest.UserInfo{UID: 100})
+	a.Equal(err, orm.ErrNoRows)
+	_, err = db.CheckAffected().Delete(&modeltest.UserInfo{UID: 2})
+	a.NotError(err)
+	hasCount(db, a, "user_info", 0)
+
+	tx, err := db.Begin()
+	a.NotError(err)
+	_, err = tx.CheckAffected().Delete(&modeltest.UserInfo{UID: 100})
+	a.Equal(err, orm.ErrNoRows)
+	_, err = tx.Delete(&modeltest.UserInfo{UID: 100})
+	a.NotError(err)
+	a.NotError(tx.Commit())
+}
+
+func TestDB_Update_occ(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	a.NotError(db.Create(&modeltest.Account{}))
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Account{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	_, err := db.Insert(&modeltest.Account{UID: 1, Account: 10})
+	a.NotError(err)
+
+	acc1 := &modeltest.Account{UID: 1}
+	a.NotError(db.Select(acc1))
+	a.Equal(acc1.Version, 1)
+	acc2 := &modeltest.Account{UID: 1}
+	a.NotError(db.Select(acc2))
+
+	// 更新成功之后，版本号加 1，可以再次更新
+	acc1.Account = 20
+	_, err = db.Update(acc1)
+	a.NotError(err).Equal(acc1.Version, 2)
+	acc1.Account = 30
+	_, err = db.Update(acc1)
+	a.NotError(err).Equal(acc1.Version, 3)
+
+	// acc2 的版本号已经过期
+	acc2.Account = 40
+	_, err = db.Update(acc2)
+	a.Equal(err, orm.ErrOptimisticLock).Equal(acc2.Version, 1)
+
+	acc := &modeltest.Account{UID: 1}
+	a.NotError(db.Select(acc))
+	a.Equal(acc.Account, 30).Equal(acc.Version, 3)
+}
+
+// 乐观锁列未指定默认值，初始的版本号为 0
+type occNoDefault struct {
+	ID      int64  `orm:"name(id);pk"`
+	Name    string `orm:"name(name);len(20)"`
+	Version int64  `orm:"name(version);occ"`
+}
+
+func (m *occNoDefault) Meta() string {
+	return "name(occ_no_default)"
+}
+
+func TestDB_Update_occZero(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	a.NotError(db.Create(&occNoDefault{}))
+	defer func() {
+		a.NotError(db.Drop(&occNoDefault{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	_, err := db.Insert(&occNoDefault{ID: 1, Name: "n1"})
+	a.NotError(err)
+
+	obj := &occNoDefault{ID: 1}
+	a.NotError(db.Select(obj))
+	a.Equal(obj.Version, 0)
+
+	obj.Name = "n2"
+	_, err = db.Update(obj)
+	a.NotError(err).Equal(obj.Version, 1)
+	obj.Name = "n3"
+	_, err = db.Update(obj)
+	a.NotError(err).Equal(obj.Version, 2)
+
+	// 过期的版本号
+	_, err = db.Update(&occNoDefault{ID: 1, Name: "n4"})
+	a.Equal(err, orm.ErrOptimisticLock)
+
+	obj = &occNoDefault{ID: 1}
+	a.NotError(db.Select(obj))
+	a.Equal(obj.Name, "n3").Equal(obj.Version, 2)
+}
+
 func TestDB_Count(t *testing.T) {
 	a := assert.New(t)
 
@@ -294,3 +409,146 @@ func TestDB_Drop(t *testing.T) {
 	r, err := db.Insert(&modeltest.Admin{})
 	a.Error(err).Nil(r)
 }
+
+// 默认值中包含引号
+type quotedDefault struct {
+	ID   int64  `orm:"name(id);ai"`
+	Age  int64  `orm:"name(age)"`
+	Name string `orm:"name(name);len(20);default('it\\'s')"`
+}
+
+func (m *quotedDefault) Meta() string {
+	return "name(quoted_default)"
+}
+
+func TestDB_Create_quotedDefault(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&quotedDefault{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	a.NotError(db.Create(&quotedDefault{}))
+	_, err := db.Insert(&quotedDefault{Age: 1})
+	a.NotError(err)
+
+	obj := &quotedDefault{ID: 1}
+	a.NotError(db.Select(obj))
+	a.Equal(obj.Name, "it's")
+}
+
+func TestDB_MultCreate(t *testing.T) {
+	a := assert.New(t)
+
+	db, rec := ormtest.New(dialect.Mysql(), "p_")
+	defer func() {
+		a.NotError(db.Close())
+	}()
+
+	// 被引用的 groups 先创建，删除时则相反
+	a.NotError(db.MultCreate(&modeltest.Admin{}, &modeltest.Group{}))
+	queries := rec.Queries()
+	a.Contains(queries[0], "CREATE TABLE IF NOT EXISTS `p_groups`")
+	a.Contains(queries[len(queries)-1], "CREATE TABLE IF NOT EXISTS `p_administrators`")
+
+	rec.Reset()
+	a.NotError(db.MultDrop(&modeltest.Group{}, &modeltest.Admin{}))
+	queries = rec.Queries()
+	a.Equal(len(queries), 2)
+	a.Contains(queries[0], "`p_administrators`").Contains(queries[1], "`p_groups`")
+
+	// 循环引用
+	rec.Reset()
+	a.NotError(db.MultCreate(&cycleA{}, &cycleB{}))
+	queries = rec.Queries()
+	a.Equal(len(queries), 3)
+	a.Contains(queries[0], "CREATE TABLE IF NOT EXISTS `p_cycle_b`").NotContains(queries[0], "fk_b_a")
+	a.Contains(queries[1], "CREATE TABLE IF NOT EXISTS `p_cycle_a`")
+	a.True(ormtest.Equal(queries[2], "ALTER TABLE `p_cycle_b` ADD CONSTRAINT fk_b_a FOREIGN KEY(`a`) REFERENCES p_cycle_a(`id`)"), queries[2])
+
+	rec.Reset()
+	a.NotError(db.MultDrop(&cycleA{}, &cycleB{}))
+	queries = rec.Queries()
+	a.Equal(len(queries), 3)
+	a.True(ormtest.Equal(queries[0], "ALTER TABLE `p_cycle_b` DROP FOREIGN KEY fk_b_a"), queries[0])
+	a.Contains(queries[1], "`p_cycle_a`").Contains(queries[2], "`p_cycle_b`")
+
+	// sqlite3 允许外键引用尚未创建的表
+	sqlite := newDB(a)
+	defer func() {
+		a.NotError(sqlite.Close())
+		closeDB(a)
+	}()
+	a.NotError(sqlite.MultCreate(&cycleA{}, &cycleB{}))
+	a.NotError(sqlite.MultDrop(&cycleA{}, &cycleB{}))
+}
+
+func TestDB_NamedArgs(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	rows, err := db.Query("SELECT * FROM #user_info WHERE {uid}=@uid OR ({firstName}=@first AND {uid}<>@uid)",
+		sql.Named("uid", 1),
+		sql.Named("first", "f2"),
+	)
+	a.NotError(err).NotNil(rows)
+	data, err := fetch.Map(false, rows)
+	a.NotError(err).Equal(2, len(data))
+	a.NotError(rows.Close())
+
+	stmt := db.SQL().Select().
+		Select("*").
+		From("{#user_info}").
+		Where("{uid}>@uid", sql.Named("uid", 0)).
+		Asc("{uid}").
+		Limit(sql.Named("limit", 1), sql.Named("offset", 1))
+	u := &modeltest.UserInfo{}
+	cnt, err := stmt.QueryObj(u)
+	a.NotError(err).Equal(1, cnt)
+	a.Equal(u.UID, 2)
+}
+
+func TestDB_WithPrefix(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	t1 := db.WithPrefix("t1_")
+	a.Equal(t1.TablePrefix(), "t1_").Equal(db.TablePrefix(), prefix)
+	a.True(t1.StdDB() == db.StdDB())
+	a.NotError(t1.Create(&modeltest.Group{}))
+	a.NotError(db.WithPrefix("t2_").Create(&modeltest.Group{}))
+	defer func() {
+		a.NotError(t1.Drop(&modeltest.Group{}))
+		a.NotError(db.WithPrefix("t2_").Drop(&modeltest.Group{}))
+	}()
+
+	_, err := t1.Insert(&modeltest.Group{Name: "g1"})
+	a.NotError(err)
+
+	// 事务中切换前缀
+	tx, err := db.Begin()
+	a.NotError(err)
+	tx2 := tx.WithPrefix("t2_")
+	a.Equal(tx2.TablePrefix(), "t2_")
+	_, err = tx2.Insert(&modeltest.Group{Name: "g2"})
+	a.NotError(err)
+	_, err = tx2.Insert(&modeltest.Group{Name: "g3"})
+	a.NotError(err)
+	_, err = tx.WithPrefix("t1_").Insert(&modeltest.Group{Name: "g4"})
+	a.NotError(err)
+	a.NotError(tx.Commit())
+
+	hasCount(t1, a, "groups", 2)
+	hasCount(db.WithPrefix("t2_"), a, "groups", 2)
+}
@@ -7,7 +7,11 @@ package dialect
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/issue9/orm"
@@ -49,7 +53,7 @@ func createColSQL(b base, buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 
 	if col.HasDefault {
 		buf.WriteString(" DEFAULT '").
-			WriteString(col.Default).
+			WriteString(strings.ReplaceAll(col.Default, "'", "''")).
 			WriteByte('\'')
 	}
 
@@ -109,6 +113,35 @@ func createFKSQL(buf *sqlbuilder.SQLBuilder, fk *orm.ForeignKey, fkName string)
 	}
 }
 
+// 为已经存在的表添加外键的语句：
+//  ALTER TABLE {#tbl} ADD CONSTRAINT fk_name FOREIGN KEY ...
+func addFKSQL(m *orm.Model, fkName string) (string, error) {
+	fk, found := m.FK[fkName]
+	if !found {
+		return "", fmt.Errorf("表 %s 中不存在外键 %s", m.Name, fkName)
+	}
+
+	buf := sqlbuilder.New("ALTER TABLE {#").WriteString(m.Name).WriteString("} ADD")
+	createFKSQL(buf, fk, fkName)
+	return buf.String(), nil
+}
+
+// 删除外键的语句，drop 为各数据库删除外键时使用的关键字：
+//  ALTER TABLE {#tbl} DROP FOREIGN KEY|CONSTRAINT fk_name
+func dropFKSQL(m *orm.Model, fkName, drop string) (string, error) {
+	if _, found := m.FK[fkName]; !found {
+		return "", fmt.Errorf("表 %s 中不存在外键 %s", m.Name, fkName)
+	}
+
+	return sqlbuilder.New("ALTER TABLE {#").
+		WriteString(m.Name).
+		WriteString("} DROP ").
+		WriteString(drop).
+		WriteByte(' ').
+		WriteString(fkName).
+		String(), nil
+}
+
 // create table 语句中 check 约束部分的语句
 func createCheckSQL(buf *sqlbuilder.SQLBuilder, expr, chkName string) {
 	// CONSTRAINT chk_name CHECK (id>0 AND username='admin')
@@ -164,6 +197,40 @@ func createIndexSQL(model *orm.Model) ([]string, error) {
 	return sqls, nil
 }
 
+// t 是否为 []byte 或是 [n]byte 等字节数组
+func isBytes(t reflect.Type) bool {
+	kind := t.Elem().Kind()
+	return kind == reflect.Uint8 || kind == reflect.Int8
+}
+
+// 返回 model 中各约束的名称，types 指定了需要返回的约束类型，
+// 可以是 unique、index、fk 和 check。
+func constraintNames(model *orm.Model, types ...string) []string {
+	names := make([]string, 0, 10)
+	for _, typ := range types {
+		switch typ {
+		case "unique":
+			for name := range model.UniqueIndexes {
+				names = append(names, name)
+			}
+		case "index":
+			for name := range model.KeyIndexes {
+				names = append(names, name)
+			}
+		case "fk":
+			for name := range model.FK {
+				names = append(names, name)
+			}
+		case "check":
+			for name := range model.Check {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
 // mysql 系列数据库分页语法的实现。支持以下数据库：
 // MySQL, H2, HSQLDB, Postgres, SQLite3
 func mysqlLimitSQL(limit interface{}, offset ...interface{}) (string, []interface{}) {
@@ -215,3 +282,119 @@ func oracleLimitSQL(limit interface{}, offset ...interface{}) (string, []interfa
 
 	return query, []interface{}{offset[0], limit}
 }
+
+// 标准的锁定子句，适用于 postgres 和 mysql 8.0 及之后的版本：
+//  FOR UPDATE|SHARE [OF tbl1,tbl2] [NOWAIT|SKIP LOCKED]
+func standardLockSQL(lock *sqlbuilder.Lock) (string, error) {
+	buf := sqlbuilder.New(" FOR ")
+	if lock.Share {
+		buf.WriteString("SHARE")
+	} else {
+		buf.WriteString("UPDATE")
+	}
+
+	if len(lock.Of) > 0 {
+		buf.WriteString(" OF ")
+		for _, tbl := range lock.Of {
+			buf.WriteString(tbl).WriteByte(',')
+		}
+		buf.TruncateLast(1)
+	}
+
+	switch lock.Wait {
+	case sqlbuilder.LockWaitDefault:
+	case sqlbuilder.LockNoWait:
+		buf.WriteString(" NOWAIT")
+	case sqlbuilder.LockSkipLocked:
+		buf.WriteString(" SKIP LOCKED")
+	default:
+		return "", sqlbuilder.ErrLockNotSupported
+	}
+
+	return buf.String(), nil
+}
+
+// 以 CASE WHEN 的形式生成同时更新多条记录的语句，适用于 mysql 和 sqlite3 等数据库：
+//  UPDATE tbl SET c1=CASE WHEN k1=? THEN ? WHEN k1=? THEN ? ELSE c1 END WHERE k1 IN(?,?)
+// rowValues 表示多列的 keys 是否可以使用 (k1,k2) IN(...) 的形式，具体可参考 orm.Dialect.RowValues()。
+func caseUpdateManySQL(m *orm.Model, keys, cols []*orm.Column, rows [][]interface{}, rowValues bool) (string, []interface{}, error) {
+	if err := checkUpdateManyArgs(keys, cols, rows); err != nil {
+		return "", nil, err
+	}
+
+	buf := sqlbuilder.New("UPDATE {#").WriteString(m.Name).WriteString("} SET ")
+	args := make([]interface{}, 0, ((len(keys)+1)*len(cols)+len(keys))*len(rows))
+
+	for index, col := range cols {
+		buf.WriteByte('{').WriteString(col.Name).WriteString("}=CASE")
+		for _, row := range rows {
+			buf.WriteString(" WHEN ")
+			for i, key := range keys {
+				if i > 0 {
+					buf.WriteString(" AND ")
+				}
+				buf.WriteByte('{').WriteString(key.Name).WriteString("}=?")
+				args = append(args, row[i])
+			}
+			buf.WriteString(" THEN ?")
+			args = append(args, row[len(keys)+index])
+		}
+		buf.WriteString(" ELSE {").WriteString(col.Name).WriteString("} END,")
+	}
+	buf.TruncateLast(1)
+
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, "{"+key.Name+"}")
+	}
+	vals := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		vals = append(vals, row[:len(keys)])
+	}
+	w := sqlbuilder.Where()
+	if rowValues {
+		w.AndIn(names, vals...)
+	} else {
+		w.AndInExpand(names, vals...)
+	}
+	wq, wa, err := w.SQL()
+	if err != nil {
+		return "", nil, err
+	}
+
+	buf.WriteString(" WHERE").WriteString(wq)
+	return buf.String(), append(args, wa...), nil
+}
+
+func checkUpdateManyArgs(keys, cols []*orm.Column, rows [][]interface{}) error {
+	if len(keys) == 0 || len(cols) == 0 {
+		return sqlbuilder.ErrColumnsIsEmpty
+	}
+
+	if len(rows) == 0 {
+		return sqlbuilder.ErrValueIsEmpty
+	}
+
+	for _, row := range rows {
+		if len(row) != len(keys)+len(cols) {
+			return sqlbuilder.ErrArgsNotMatch
+		}
+	}
+
+	return nil
+}
+
+// err 是否已经被 TranslateError() 转换过
+func isTranslated(err error) bool {
+	var ce *orm.ConstraintError
+	return errors.As(err, &ce)
+}
+
+// 返回 expr 在 s 中匹配的第一个子表达式的内容
+func submatch(expr *regexp.Regexp, s string) string {
+	matches := expr.FindStringSubmatch(s)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
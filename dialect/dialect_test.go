@@ -7,10 +7,12 @@ package dialect
 import (
 	"database/sql"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/modeltest"
 	"github.com/issue9/orm/internal/sqltest"
 	"github.com/issue9/orm/sqlbuilder"
 )
@@ -49,6 +51,18 @@ func TestCreatColSQL(t *testing.T) {
 	wont = "{id} SMALLINT NOT NULL DEFAULT '1'"
 	sqltest.Equal(a, buf.String(), wont)
 
+	// 默认值中包含引号
+	buf.Reset()
+	col.GoType = reflect.TypeOf("")
+	col.Len1 = 20
+	col.Default = "it's"
+	createColSQL(dialect, buf, col)
+	wont = "{id} VARCHAR(20) NOT NULL DEFAULT 'it''s'"
+	sqltest.Equal(a, buf.String(), wont)
+
+	col.GoType = reflect.TypeOf(int8(1))
+	col.Len1 = 0
+
 	buf.Reset()
 	col.HasDefault = false
 	col.Nullable = true
@@ -106,6 +120,42 @@ func TestCreateFKSQL(t *testing.T) {
 	sqltest.Equal(a, buf.String(), wont)
 }
 
+func TestAddFKSQL(t *testing.T) {
+	a := assert.New(t)
+	m, err := orm.NewModel(&modeltest.Admin{})
+	a.NotError(err)
+
+	query, err := addFKSQL(m, "fk_name")
+	a.NotError(err)
+	wont := "ALTER TABLE {#administrators} ADD CONSTRAINT fk_name FOREIGN KEY({group}) REFERENCES #groups({id}) ON UPDATE NO ACTION"
+	sqltest.Equal(a, query, wont)
+
+	query, err = dropFKSQL(m, "fk_name", "CONSTRAINT")
+	a.NotError(err)
+	sqltest.Equal(a, query, "ALTER TABLE {#administrators} DROP CONSTRAINT fk_name")
+
+	_, err = addFKSQL(m, "not-exists")
+	a.Error(err)
+	_, err = dropFKSQL(m, "not-exists", "CONSTRAINT")
+	a.Error(err)
+}
+
+func TestGlobalConstraintNames(t *testing.T) {
+	a := assert.New(t)
+	m, err := orm.NewModel(&modeltest.Admin{})
+	a.NotError(err)
+
+	names := Mysql().GlobalConstraintNames(m)
+	sort.Strings(names)
+	a.Equal(names, []string{"chk_name", "fk_name"})
+
+	names = Postgres().GlobalConstraintNames(m)
+	sort.Strings(names)
+	a.Equal(names, []string{"administratorspk", "index_name", "unique_email", "unique_username"})
+
+	a.Equal(Sqlite3().GlobalConstraintNames(m), []string{"index_name"})
+}
+
 func TestCreateCheckSQL(t *testing.T) {
 	a := assert.New(t)
 	buf := sqlbuilder.New("")
@@ -148,3 +198,67 @@ func TestOracleLimitSQL(t *testing.T) {
 	a.Equal(ret, []interface{}{2, sql.Named("limit", 1)})
 	sqltest.Equal(a, query, "offset ? rows fetch next @limit rows only")
 }
+
+func TestStandardLockSQL(t *testing.T) {
+	a := assert.New(t)
+
+	query, err := standardLockSQL(&sqlbuilder.Lock{})
+	a.NotError(err).Equal(query, " FOR UPDATE")
+
+	query, err = standardLockSQL(&sqlbuilder.Lock{Share: true, Wait: sqlbuilder.LockNoWait})
+	a.NotError(err).Equal(query, " FOR SHARE NOWAIT")
+
+	query, err = standardLockSQL(&sqlbuilder.Lock{Wait: sqlbuilder.LockSkipLocked, Of: []string{"t1", "t2"}})
+	a.NotError(err).Equal(query, " FOR UPDATE OF t1,t2 SKIP LOCKED")
+
+	_, err = standardLockSQL(&sqlbuilder.Lock{Wait: 100})
+	a.Equal(err, sqlbuilder.ErrLockNotSupported)
+}
+
+func TestCaseUpdateManySQL(t *testing.T) {
+	a := assert.New(t)
+	m := &orm.Model{Name: "tbl"}
+	id := &orm.Column{Name: "id"}
+	name := &orm.Column{Name: "name"}
+	age := &orm.Column{Name: "age"}
+
+	query, args, err := caseUpdateManySQL(m, []*orm.Column{id}, []*orm.Column{name, age}, [][]interface{}{
+		{1, "n1", 11},
+		{2, "n2", 12},
+	}, true)
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 2, "n2", 1, 11, 2, 12, 1, 2})
+	sqltest.Equal(a, query, "UPDATE {#tbl} SET "+
+		"{name}=CASE WHEN {id}=? THEN ? WHEN {id}=? THEN ? ELSE {name} END,"+
+		"{age}=CASE WHEN {id}=? THEN ? WHEN {id}=? THEN ? ELSE {age} END "+
+		"WHERE {id} IN(?,?)")
+
+	// 联合主键
+	query, args, err = caseUpdateManySQL(m, []*orm.Column{id, name}, []*orm.Column{age}, [][]interface{}{
+		{1, "n1", 11},
+	}, true)
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 11, 1, "n1"})
+	sqltest.Equal(a, query, "UPDATE {#tbl} SET "+
+		"{age}=CASE WHEN {id}=? AND {name}=? THEN ? ELSE {age} END "+
+		"WHERE ({id},{name}) IN((?,?))")
+
+	// 联合主键，不支持多列比较
+	query, args, err = caseUpdateManySQL(m, []*orm.Column{id, name}, []*orm.Column{age}, [][]interface{}{
+		{1, "n1", 11},
+		{2, "n2", 12},
+	}, false)
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 11, 2, "n2", 12, 1, "n1", 2, "n2"})
+	sqltest.Equal(a, query, "UPDATE {#tbl} SET "+
+		"{age}=CASE WHEN {id}=? AND {name}=? THEN ? WHEN {id}=? AND {name}=? THEN ? ELSE {age} END "+
+		"WHERE (({id}=? AND {name}=?) OR ({id}=? AND {name}=?))")
+
+	// 参数错误
+	_, _, err = caseUpdateManySQL(m, []*orm.Column{id}, nil, [][]interface{}{{1}}, true)
+	a.Equal(err, sqlbuilder.ErrColumnsIsEmpty)
+	_, _, err = caseUpdateManySQL(m, []*orm.Column{id}, []*orm.Column{name}, nil, true)
+	a.Equal(err, sqlbuilder.ErrValueIsEmpty)
+	_, _, err = caseUpdateManySQL(m, []*orm.Column{id}, []*orm.Column{name}, [][]interface{}{{1}}, true)
+	a.Equal(err, sqlbuilder.ErrArgsNotMatch)
+}
@@ -5,10 +5,18 @@
 package dialect
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
 
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/sqlbuilder"
@@ -16,6 +24,17 @@ import (
 
 var mysqlInst *mysql
 
+// 从 mysql 的错误信息中提取约束名和列名
+var (
+	mysqlKeyExpr    = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlFKExpr     = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlColumnExpr = regexp.MustCompile(`(?:Column|Field) '([^']+)'`)
+	mysqlCheckExpr  = regexp.MustCompile(`[Cc]heck constraint '([^']+)'`)
+)
+
+// 用于生成 BulkLoad 中唯一的 Reader 名称
+var mysqlBulkLoadID uint64
+
 type mysql struct{}
 
 // Mysql 返回一个适配 mysql 的 Dialect 接口
@@ -23,6 +42,8 @@ type mysql struct{}
 // 支持以下 meta 属性
 //  charset 字符集，语法为： charset(utf-8)
 //  engine 使用的引擎，语法为： engine(innodb)
+//
+// 实现了 orm.BulkLoader 接口，需要服务端开启 local_infile 选项。
 func Mysql() orm.Dialect {
 	if mysqlInst == nil {
 		mysqlInst = &mysql{}
@@ -128,14 +149,137 @@ func (m *mysql) LimitSQL(limit interface{}, offset ...interface{}) (string, []in
 	return mysqlLimitSQL(limit, offset...)
 }
 
+// 仅指定了共享锁时，使用 LOCK IN SHARE MODE 以兼容 8.0 之前的版本；
+// 其它情况下使用 8.0 之后的标准语法。
+func (m *mysql) LockSQL(lock *sqlbuilder.Lock) (string, error) {
+	if lock.Share && lock.Wait == sqlbuilder.LockWaitDefault && len(lock.Of) == 0 {
+		return " LOCK IN SHARE MODE", nil
+	}
+	return standardLockSQL(lock)
+}
+
+func (m *mysql) AddForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return addFKSQL(model, fkName)
+}
+
+func (m *mysql) DropForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return dropFKSQL(model, fkName, "FOREIGN KEY")
+}
+
+// InnoDB 中外键和 check 的约束名需要在整个数据库中唯一，
+// 索引名称则只需要在表内唯一。
+func (m *mysql) GlobalConstraintNames(model *orm.Model) []string {
+	return constraintNames(model, "fk", "check")
+}
+
 func (m *mysql) TruncateTableSQL(model *orm.Model) []string {
 	return []string{"TRUNCATE TABLE #" + model.Name}
 }
 
+// 通过 LOAD DATA LOCAL INFILE 导入数据。
+//
+// 数据通过 github.com/go-sql-driver/mysql 的 RegisterReaderHandler
+// 以流的形式发送，不会产生临时文件，但要求服务端开启了 local_infile 选项。
+func (m *mysql) BulkLoad(tx *orm.Tx, table string, cols []string, next func() ([]interface{}, error)) (int64, error) {
+	name := "orm_bulk_load_" + strconv.FormatUint(atomic.AddUint64(&mysqlBulkLoadID, 1), 10)
+
+	r, w := io.Pipe()
+	mysqldriver.RegisterReaderHandler(name, func() io.Reader { return r })
+	defer mysqldriver.DeregisterReaderHandler(name)
+
+	done := make(chan error, 1) // next() 或是编码过程中产生的错误
+	go func() {
+		err := writeMysqlBulkRows(w, next)
+		w.CloseWithError(err)
+		done <- err
+	}()
+
+	query := sqlbuilder.New("LOAD DATA LOCAL INFILE 'Reader::").
+		WriteString(name).
+		WriteString("' INTO TABLE {#").
+		WriteString(table).
+		WriteString("} FIELDS TERMINATED BY '\\t' ESCAPED BY '\\\\' LINES TERMINATED BY '\\n' (")
+	for _, col := range cols {
+		query.WriteByte('{').WriteString(col).WriteString("},")
+	}
+	query.TruncateLast(1).WriteByte(')')
+
+	rslt, err := tx.Exec(query.String())
+	r.Close() // 确保写入的 goroutine 可以退出
+	if loadErr := <-done; loadErr != nil && loadErr != io.ErrClosedPipe {
+		return 0, loadErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return rslt.RowsAffected()
+}
+
 func (m *mysql) TransactionalDDL() bool {
 	return false
 }
 
+func (m *mysql) UpdateManySQL(model *orm.Model, keys, cols []*orm.Column, rows [][]interface{}) (string, []interface{}, error) {
+	return caseUpdateManySQL(model, keys, cols, rows, true)
+}
+
+func (m *mysql) RowValues() bool {
+	return true
+}
+
+// 未开启 NO_BACKSLASH_ESCAPES 时，\ 为转义字符。
+func (m *mysql) BackslashEscapes() bool {
+	return true
+}
+
+// mysql 的预编译语句最多支持 65535 个占位符
+func (m *mysql) MaxArgs() int {
+	return 65535
+}
+
+// 1213 为死锁，1205 为等待锁超时
+func (m *mysql) Retryable(err error) bool {
+	var e *mysqldriver.MySQLError
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	return e.Number == 1213 || e.Number == 1205
+}
+
+func (m *mysql) TranslateError(err error) error {
+	var e *mysqldriver.MySQLError
+	if isTranslated(err) || !errors.As(err, &e) {
+		return err
+	}
+
+	ce := &orm.ConstraintError{Raw: err}
+	switch e.Number {
+	case 1062: // Duplicate entry 'x' for key 'name'
+		ce.Err = orm.ErrUniqueViolation
+		ce.Constraint = submatch(mysqlKeyExpr, e.Message)
+
+		// mysql 8.0 之后为 table.name 的形式
+		if index := strings.LastIndexByte(ce.Constraint, '.'); index >= 0 {
+			ce.Constraint = ce.Constraint[index+1:]
+		}
+	case 1451, 1452: // a foreign key constraint fails (..., CONSTRAINT `name` FOREIGN KEY ...)
+		ce.Err = orm.ErrForeignKeyViolation
+		ce.Constraint = submatch(mysqlFKExpr, e.Message)
+	case 1048, 1364: // Column 'x' cannot be null 或是 Field 'x' doesn't have a default value
+		ce.Err = orm.ErrNotNullViolation
+		ce.Column = submatch(mysqlColumnExpr, e.Message)
+	case 3819: // Check constraint 'name' is violated.
+		ce.Err = orm.ErrCheckViolation
+		ce.Constraint = submatch(mysqlCheckExpr, e.Message)
+	default:
+		return err
+	}
+
+	return ce
+}
+
 func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 	if col == nil {
 		return errors.New("sqlType:col参数是个空值")
@@ -192,13 +336,16 @@ func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 	case reflect.String:
 		if col.Len1 == -1 || col.Len1 > 65533 {
 			buf.WriteString("LONGTEXT")
+		} else if col.Len1 <= 0 {
+			return errors.New("请指定长度")
 		} else {
 			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
 		}
 	case reflect.Slice, reflect.Array:
-		if col.GoType.Elem().Kind() == reflect.Int8 {
-			buf.WriteString("BLOB")
+		if !isBytes(col.GoType) {
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
+		buf.WriteString("BLOB")
 	case reflect.Struct:
 		switch col.GoType {
 		case rawBytes:
@@ -216,11 +363,15 @@ func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 		case nullString:
 			if col.Len1 == -1 || col.Len1 > 65533 {
 				buf.WriteString("LONGTEXT")
+			} else if col.Len1 <= 0 {
+				return errors.New("请指定长度")
 			} else {
 				buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
 			}
 		case timeType:
 			buf.WriteString("DATETIME")
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
 	default:
 		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
@@ -228,3 +379,93 @@ func (m *mysql) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 
 	return nil
 }
+
+// 将 next 返回的数据按 LOAD DATA 的默认格式写入 w，
+// 字段之间以 \t 分隔，行之间以 \n 分隔，NULL 以 \N 表示。
+func writeMysqlBulkRows(w io.Writer, next func() ([]interface{}, error)) error {
+	buf := make([]byte, 0, 1024)
+	for {
+		vals, err := next()
+		if err != nil {
+			return err
+		}
+		if vals == nil {
+			return nil
+		}
+
+		buf = buf[:0]
+		for i, v := range vals {
+			if i > 0 {
+				buf = append(buf, '\t')
+			}
+			if buf, err = appendMysqlBulkValue(buf, v); err != nil {
+				return err
+			}
+		}
+		buf = append(buf, '\n')
+
+		if _, err = w.Write(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// 将 v 转换成 LOAD DATA 可以识别的格式，并添加到 buf 中。
+func appendMysqlBulkValue(buf []byte, v interface{}) ([]byte, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = val
+	}
+
+	switch val := v.(type) {
+	case nil:
+		return append(buf, '\\', 'N'), nil
+	case []byte:
+		return appendMysqlBulkEscape(buf, string(val)), nil
+	case string:
+		return appendMysqlBulkEscape(buf, val), nil
+	case bool:
+		if val {
+			return append(buf, '1'), nil
+		}
+		return append(buf, '0'), nil
+	case int64:
+		return strconv.AppendInt(buf, val, 10), nil
+	case float64:
+		return strconv.AppendFloat(buf, val, 'g', -1, 64), nil
+	case time.Time:
+		return val.AppendFormat(buf, "2006-01-02 15:04:05.999999"), nil
+	}
+
+	val, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(val) == reflect.TypeOf(v) { // 无法再作转换
+		return nil, fmt.Errorf("不支持的类型:[%T]", v)
+	}
+	return appendMysqlBulkValue(buf, val)
+}
+
+func appendMysqlBulkEscape(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case 0:
+			buf = append(buf, '\\', '0')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
@@ -5,17 +5,26 @@
 package dialect
 
 import (
+	"bytes"
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/issue9/assert"
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/internal/sqltest"
 	"github.com/issue9/orm/sqlbuilder"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
 )
 
-var _ base = &mysql{}
+var (
+	_ base           = &mysql{}
+	_ orm.BulkLoader = &mysql{}
+)
 
 func TestMysql_CreateTableOptions(t *testing.T) {
 	a := assert.New(t)
@@ -80,4 +89,111 @@ func TestMysql_sqlType(t *testing.T) {
 	buf.Reset()
 	a.NotError(m.sqlType(buf, col))
 	sqltest.Equal(a, buf.String(), "BIGINT(5)")
+
+	// []byte
+	col.GoType = reflect.TypeOf([]byte{})
+	buf.Reset()
+	a.NotError(m.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BLOB")
+
+	// 未指定长度的字符串，返回错误
+	col.GoType = reflect.TypeOf("")
+	col.Len1 = 0
+	a.Error(m.sqlType(buf, col))
+	col.GoType = reflect.TypeOf(sql.NullString{})
+	a.Error(m.sqlType(buf, col))
+
+	// 不支持的类型
+	col.GoType = reflect.TypeOf([]string{})
+	a.Error(m.sqlType(buf, col))
+	col.GoType = reflect.TypeOf(struct{}{})
+	a.Error(m.sqlType(buf, col))
+}
+
+func TestWriteMysqlBulkRows(t *testing.T) {
+	a := assert.New(t)
+
+	rows := [][]interface{}{
+		{1, "a\tb\nc\\d", nil, true},
+		{int8(2), []byte("bytes"), sql.NullString{}, false},
+		{uint(3), sql.NullString{Valid: true, String: "s"}, 1.5, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	next := func() ([]interface{}, error) {
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	}
+
+	buf := new(bytes.Buffer)
+	a.NotError(writeMysqlBulkRows(buf, next))
+	a.Equal(buf.String(), "1\ta\\tb\\nc\\\\d\t\\N\t1\n"+
+		"2\tbytes\t\\N\t0\n"+
+		"3\ts\t1.5\t2018-01-02 03:04:05\n")
+
+	// 不支持的类型
+	rows = [][]interface{}{{struct{}{}}}
+	a.Error(writeMysqlBulkRows(buf, next))
+}
+
+func TestMysql_Retryable(t *testing.T) {
+	a := assert.New(t)
+	m := &mysql{}
+
+	a.True(m.Retryable(&mysqldriver.MySQLError{Number: 1213}))
+	a.True(m.Retryable(fmt.Errorf("wrap: %w", &mysqldriver.MySQLError{Number: 1205})))
+	a.False(m.Retryable(&mysqldriver.MySQLError{Number: 1062}))
+	a.False(m.Retryable(errors.New("1213")))
+	a.False(m.Retryable(nil))
+}
+
+func TestMysql_LockSQL(t *testing.T) {
+	a := assert.New(t)
+	m := &mysql{}
+
+	query, err := m.LockSQL(&sqlbuilder.Lock{Share: true})
+	a.NotError(err).Equal(query, " LOCK IN SHARE MODE")
+
+	query, err = m.LockSQL(&sqlbuilder.Lock{Share: true, Wait: sqlbuilder.LockSkipLocked})
+	a.NotError(err).Equal(query, " FOR SHARE SKIP LOCKED")
+
+	query, err = m.LockSQL(&sqlbuilder.Lock{})
+	a.NotError(err).Equal(query, " FOR UPDATE")
+}
+
+func TestMysql_TranslateError(t *testing.T) {
+	a := assert.New(t)
+	m := &mysql{}
+
+	raw := &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'tbl.unique_name'"}
+	err := m.TranslateError(raw)
+	ce, ok := err.(*orm.ConstraintError)
+	a.True(ok).NotNil(ce)
+	a.Equal(ce.Err, orm.ErrUniqueViolation).Equal(ce.Constraint, "unique_name").Equal(ce.Raw, raw)
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+	a.Equal(m.TranslateError(err), err) // 不会重复转换
+
+	err = m.TranslateError(&mysqldriver.MySQLError{
+		Number:  1452,
+		Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`tbl`, CONSTRAINT `fk_name` FOREIGN KEY (`gid`) REFERENCES `groups` (`id`))",
+	})
+	a.True(errors.Is(err, orm.ErrForeignKeyViolation))
+	a.Equal(err.(*orm.ConstraintError).Constraint, "fk_name")
+
+	err = m.TranslateError(&mysqldriver.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"})
+	a.True(errors.Is(err, orm.ErrNotNullViolation))
+	a.Equal(err.(*orm.ConstraintError).Column, "name")
+
+	err = m.TranslateError(&mysqldriver.MySQLError{Number: 3819, Message: "Check constraint 'chk_name' is violated."})
+	a.True(errors.Is(err, orm.ErrCheckViolation))
+	a.Equal(err.(*orm.ConstraintError).Constraint, "chk_name")
+
+	// 无法识别
+	raw = &mysqldriver.MySQLError{Number: 1213}
+	a.Equal(m.TranslateError(raw), raw)
+	other := errors.New("other")
+	a.Equal(m.TranslateError(other), other)
+	a.Nil(m.TranslateError(nil))
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/sqlbuilder"
+	"github.com/lib/pq"
 )
 
 var postgresInst *postgres
@@ -92,6 +93,27 @@ func (p *postgres) LimitSQL(limit interface{}, offset ...interface{}) (string, [
 	return mysqlLimitSQL(limit, offset...)
 }
 
+func (p *postgres) LockSQL(lock *sqlbuilder.Lock) (string, error) {
+	return standardLockSQL(lock)
+}
+
+func (p *postgres) AddForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return addFKSQL(model, fkName)
+}
+
+func (p *postgres) DropForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return dropFKSQL(model, fkName, "CONSTRAINT")
+}
+
+// 主键和唯一约束会创建同名的索引，与普通索引一样，名称需要在整个 schema 中唯一。
+func (p *postgres) GlobalConstraintNames(model *orm.Model) []string {
+	names := constraintNames(model, "unique", "index")
+	if len(model.PK) > 0 {
+		names = append(names, model.Name+pkName)
+	}
+	return names
+}
+
 func (p *postgres) TruncateTableSQL(m *orm.Model) []string {
 	w := sqlbuilder.New("TRUNCATE TABLE #").WriteString(m.Name)
 
@@ -102,10 +124,170 @@ func (p *postgres) TruncateTableSQL(m *orm.Model) []string {
 	return []string{w.String()}
 }
 
+// 通过 COPY FROM STDIN 导入数据，由 github.com/lib/pq 的 CopyIn 功能实现。
+func (p *postgres) BulkLoad(tx *orm.Tx, table string, cols []string, next func() ([]interface{}, error)) (int64, error) {
+	w := sqlbuilder.New("COPY {#").WriteString(table).WriteString("}(")
+	for _, col := range cols {
+		w.WriteByte('{').WriteString(col).WriteString("},")
+	}
+	w.TruncateLast(1).WriteString(") FROM STDIN")
+
+	stmt, err := tx.Prepare(w.String())
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var count int64
+	for {
+		vals, err := next()
+		if err != nil {
+			return 0, err
+		}
+		if vals == nil {
+			break
+		}
+
+		if _, err = stmt.Exec(vals...); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	// 不带参数的 Exec 表示数据已经发送完毕
+	if _, err = stmt.Exec(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (p *postgres) TransactionalDDL() bool {
 	return true
 }
 
+// 以 UPDATE ... FROM (VALUES ...) 的形式同时更新多条记录：
+//  UPDATE tbl SET c1=v.c1 FROM (VALUES(CAST(? AS BIGINT),CAST(? AS TEXT)),(?,?)) AS v(k1,c1) WHERE tbl.k1=v.k1
+// 第一行的值会显式地转换成列的类型，否则 postgresql 会将其当作 text 处理。
+func (p *postgres) UpdateManySQL(model *orm.Model, keys, cols []*orm.Column, rows [][]interface{}) (string, []interface{}, error) {
+	if err := checkUpdateManyArgs(keys, cols, rows); err != nil {
+		return "", nil, err
+	}
+
+	all := make([]*orm.Column, 0, len(keys)+len(cols))
+	all = append(append(all, keys...), cols...)
+
+	buf := sqlbuilder.New("UPDATE {#").WriteString(model.Name).WriteString("} SET ")
+	for _, col := range cols {
+		buf.WriteByte('{').WriteString(col.Name).WriteString("}=v.{").WriteString(col.Name).WriteString("},")
+	}
+	buf.TruncateLast(1)
+
+	args := make([]interface{}, 0, len(all)*len(rows))
+	buf.WriteString(" FROM (VALUES")
+	for index, row := range rows {
+		buf.WriteByte('(')
+		for i, col := range all {
+			if index > 0 {
+				buf.WriteString("?,")
+			} else {
+				buf.WriteString("CAST(? AS ")
+				if err := p.castType(buf, col); err != nil {
+					return "", nil, err
+				}
+				buf.WriteString("),")
+			}
+			args = append(args, row[i])
+		}
+		buf.TruncateLast(1).WriteString("),")
+	}
+	buf.TruncateLast(1).WriteString(") AS v(")
+	for _, col := range all {
+		buf.WriteByte('{').WriteString(col.Name).WriteString("},")
+	}
+	buf.TruncateLast(1).WriteString(") WHERE ")
+
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString("{#").WriteString(model.Name).WriteString("}.{").WriteString(key.Name).
+			WriteString("}=v.{").WriteString(key.Name).WriteByte('}')
+	}
+
+	return buf.String(), args, nil
+}
+
+func (p *postgres) RowValues() bool {
+	return true
+}
+
+// standard_conforming_strings 默认开启，普通字符串中的 \ 不作转义。
+func (p *postgres) BackslashEscapes() bool {
+	return false
+}
+
+// 与 sqlType 相同，但自增列会被转换成其对应的整数类型，以用于 CAST 表达式。
+func (p *postgres) castType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
+	typ := sqlbuilder.New("")
+	if err := p.sqlType(typ, col); err != nil {
+		return err
+	}
+
+	switch typ.String() {
+	case "SERIAL":
+		buf.WriteString("INT")
+	case "BIGSERIAL":
+		buf.WriteString("BIGINT")
+	default:
+		buf.WriteString(typ.String())
+	}
+
+	return nil
+}
+
+// postgresql 的通信协议中，参数数量以 16 位整数表示
+func (p *postgres) MaxArgs() int {
+	return 65535
+}
+
+// 40001 为序列化失败，40P01 为死锁
+func (p *postgres) Retryable(err error) bool {
+	var e *pq.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	return e.Code == "40001" || e.Code == "40P01"
+}
+
+func (p *postgres) TranslateError(err error) error {
+	var e *pq.Error
+	if isTranslated(err) || !errors.As(err, &e) {
+		return err
+	}
+
+	ce := &orm.ConstraintError{
+		Constraint: e.Constraint,
+		Column:     e.Column,
+		Raw:        err,
+	}
+	switch e.Code {
+	case "23505":
+		ce.Err = orm.ErrUniqueViolation
+	case "23503":
+		ce.Err = orm.ErrForeignKeyViolation
+	case "23502":
+		ce.Err = orm.ErrNotNullViolation
+	case "23514":
+		ce.Err = orm.ErrCheckViolation
+	default:
+		return err
+	}
+
+	return ce
+}
+
 // implement base.sqlType
 // 将col转换成sql类型，并写入buf中。
 func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
@@ -146,13 +328,16 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 	case reflect.String:
 		if col.Len1 == -1 || col.Len1 > 65533 {
 			buf.WriteString("TEXT")
+		} else if col.Len1 <= 0 {
+			return errors.New("请指定长度")
 		} else {
 			buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
 		}
 	case reflect.Slice, reflect.Array:
-		if col.GoType.Elem().Kind() == reflect.Int8 {
-			buf.WriteString("BYTEA")
+		if !isBytes(col.GoType) {
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
+		buf.WriteString("BYTEA")
 	case reflect.Struct:
 		switch col.GoType {
 		case rawBytes:
@@ -173,11 +358,15 @@ func (p *postgres) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 		case nullString:
 			if col.Len1 == -1 || col.Len1 > 65533 {
 				buf.WriteString("TEXT")
+			} else if col.Len1 <= 0 {
+				return errors.New("请指定长度")
 			} else {
 				buf.WriteString(fmt.Sprintf("VARCHAR(%d)", col.Len1))
 			}
 		case timeType:
 			buf.WriteString("TIME")
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
 	default:
 		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType.Name())
@@ -6,6 +6,7 @@ package dialect
 
 import (
 	"database/sql"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -13,9 +14,13 @@ import (
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/internal/sqltest"
 	"github.com/issue9/orm/sqlbuilder"
+	"github.com/lib/pq"
 )
 
-var _ base = &postgres{}
+var (
+	_ base           = &postgres{}
+	_ orm.BulkLoader = &postgres{}
+)
 
 func TestPostgres_sqlType(t *testing.T) {
 	p := &postgres{}
@@ -50,6 +55,22 @@ func TestPostgres_sqlType(t *testing.T) {
 	buf.Reset()
 	a.NotError(p.sqlType(buf, col))
 	sqltest.Equal(a, buf.String(), "BIGINT")
+
+	col.GoType = reflect.TypeOf([]byte{})
+	buf.Reset()
+	a.NotError(p.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BYTEA")
+
+	// 未指定长度的字符串
+	col.GoType = reflect.TypeOf("")
+	col.Len1 = 0
+	a.Error(p.sqlType(buf, col))
+
+	// 不支持的类型
+	col.GoType = reflect.TypeOf([]string{})
+	a.Error(p.sqlType(buf, col))
+	col.GoType = reflect.TypeOf(struct{}{})
+	a.Error(p.sqlType(buf, col))
 }
 
 func TestPostgres_SQL(t *testing.T) {
@@ -92,3 +113,64 @@ func BenchmarkPostgres_SQL(b *testing.B) {
 		p.SQL(s1)
 	}
 }
+
+func TestPostgres_UpdateManySQL(t *testing.T) {
+	a := assert.New(t)
+	p := &postgres{}
+
+	m := &orm.Model{Name: "tbl"}
+	id := &orm.Column{Name: "id", GoType: reflect.TypeOf(int64(1))}
+	name := &orm.Column{Name: "name", GoType: reflect.TypeOf(""), Len1: 20}
+
+	query, args, err := p.UpdateManySQL(m, []*orm.Column{id}, []*orm.Column{name}, [][]interface{}{
+		{1, "n1"},
+		{2, "n2"},
+	})
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 2, "n2"})
+	sqltest.Equal(a, query, "UPDATE {#tbl} SET {name}=v.{name} "+
+		"FROM (VALUES(CAST(? AS BIGINT),CAST(? AS VARCHAR(20))),(?,?)) AS v({id},{name}) "+
+		"WHERE {#tbl}.{id}=v.{id}")
+
+	_, _, err = p.UpdateManySQL(m, []*orm.Column{id}, []*orm.Column{name}, nil)
+	a.Equal(err, sqlbuilder.ErrValueIsEmpty)
+}
+
+func TestPostgres_Retryable(t *testing.T) {
+	a := assert.New(t)
+	p := &postgres{}
+
+	a.True(p.Retryable(&pq.Error{Code: "40001"}))
+	a.True(p.Retryable(&pq.Error{Code: "40P01"}))
+	a.False(p.Retryable(&pq.Error{Code: "23505"}))
+	a.False(p.Retryable(errors.New("40001")))
+	a.False(p.Retryable(nil))
+}
+
+func TestPostgres_TranslateError(t *testing.T) {
+	a := assert.New(t)
+	p := &postgres{}
+
+	raw := &pq.Error{Code: "23505", Constraint: "unique_name"}
+	err := p.TranslateError(raw)
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+	ce := err.(*orm.ConstraintError)
+	a.Equal(ce.Constraint, "unique_name").Equal(ce.Raw, raw)
+	var pqErr *pq.Error
+	a.True(errors.As(err, &pqErr))
+	a.Equal(p.TranslateError(err), err)
+
+	err = p.TranslateError(&pq.Error{Code: "23503", Constraint: "fk_name"})
+	a.True(errors.Is(err, orm.ErrForeignKeyViolation))
+
+	err = p.TranslateError(&pq.Error{Code: "23502", Column: "name"})
+	a.True(errors.Is(err, orm.ErrNotNullViolation))
+	a.Equal(err.(*orm.ConstraintError).Column, "name")
+
+	err = p.TranslateError(&pq.Error{Code: "23514", Constraint: "chk_name"})
+	a.True(errors.Is(err, orm.ErrCheckViolation))
+
+	raw = &pq.Error{Code: "40001"}
+	a.Equal(p.TranslateError(raw), raw)
+	a.Nil(p.TranslateError(nil))
+}
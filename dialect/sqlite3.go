@@ -6,8 +6,10 @@ package dialect
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/issue9/orm"
 	"github.com/issue9/orm/sqlbuilder"
@@ -107,6 +109,33 @@ func (s *sqlite3) LimitSQL(limit interface{}, offset ...interface{}) (string, []
 	return mysqlLimitSQL(limit, offset...)
 }
 
+// sqlite3 没有行锁，写事务会锁定整个数据库，所以普通的锁定子句直接忽略；
+// 但无法实现 NOWAIT 和 SKIP LOCKED 的语义，返回错误。
+func (s *sqlite3) LockSQL(lock *sqlbuilder.Lock) (string, error) {
+	if lock.Wait != sqlbuilder.LockWaitDefault {
+		return "", sqlbuilder.ErrLockNotSupported
+	}
+	return "", nil
+}
+
+// sqlite3 在创建表时并不检测外键引用的表是否存在，
+// 所以循环引用的外键直接在 CREATE TABLE 中创建即可。
+//
+// 同时 sqlite3 也不支持通过 ALTER TABLE 添加或是删除外键。
+func (s *sqlite3) AddForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return "", nil
+}
+
+func (s *sqlite3) DropForeignKeySQL(model *orm.Model, fkName string) (string, error) {
+	return "", nil
+}
+
+// 通过 CREATE INDEX 创建的索引名称需要在整个数据库中唯一，
+// 其它约束名称只在表内有效。
+func (s *sqlite3) GlobalConstraintNames(model *orm.Model) []string {
+	return constraintNames(model, "index")
+}
+
 func (s *sqlite3) TruncateTableSQL(m *orm.Model) []string {
 	ret := make([]string, 2)
 	ret[0] = sqlbuilder.New("DELETE FROM #").
@@ -124,6 +153,87 @@ func (s *sqlite3) TransactionalDDL() bool {
 	return true
 }
 
+func (s *sqlite3) UpdateManySQL(model *orm.Model, keys, cols []*orm.Column, rows [][]interface{}) (string, []interface{}, error) {
+	return caseUpdateManySQL(model, keys, cols, rows, false)
+}
+
+func (s *sqlite3) RowValues() bool {
+	return false
+}
+
+func (s *sqlite3) BackslashEscapes() bool {
+	return false
+}
+
+// SQLITE_MAX_VARIABLE_NUMBER 的默认值为 999
+func (s *sqlite3) MaxArgs() int {
+	return 999
+}
+
+// SQLITE_BUSY 和 SQLITE_LOCKED
+//
+// 为了不引入 cgo 的依赖，通过错误信息判断，而不是 go-sqlite3 中的错误代码。
+func (s *sqlite3) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked")
+}
+
+// 同样通过错误信息判断，其格式如下：
+//  UNIQUE constraint failed: tbl.col1, tbl.col2
+//  UNIQUE constraint failed: index 'name'
+//  FOREIGN KEY constraint failed
+//  NOT NULL constraint failed: tbl.col
+//  CHECK constraint failed: name
+func (s *sqlite3) TranslateError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+
+	msg := err.Error()
+	ce := &orm.ConstraintError{Raw: err}
+	switch {
+	case strings.HasPrefix(msg, "UNIQUE constraint failed: "):
+		ce.Err = orm.ErrUniqueViolation
+		detail := strings.TrimPrefix(msg, "UNIQUE constraint failed: ")
+		if strings.HasPrefix(detail, "index '") {
+			ce.Constraint = strings.TrimSuffix(strings.TrimPrefix(detail, "index '"), "'")
+		} else {
+			ce.Column = sqlite3Columns(detail)
+		}
+	case strings.HasPrefix(msg, "FOREIGN KEY constraint failed"):
+		ce.Err = orm.ErrForeignKeyViolation
+	case strings.HasPrefix(msg, "NOT NULL constraint failed: "):
+		ce.Err = orm.ErrNotNullViolation
+		ce.Column = sqlite3Columns(strings.TrimPrefix(msg, "NOT NULL constraint failed: "))
+	case strings.HasPrefix(msg, "CHECK constraint failed: "):
+		ce.Err = orm.ErrCheckViolation
+		ce.Constraint = strings.TrimPrefix(msg, "CHECK constraint failed: ")
+	default:
+		return err
+	}
+
+	return ce
+}
+
+// 将 tbl.col1, tbl.col2 转换成 col1,col2
+func sqlite3Columns(detail string) string {
+	cols := strings.Split(detail, ",")
+	for i, col := range cols {
+		col = strings.TrimSpace(col)
+		if index := strings.LastIndexByte(col, '.'); index >= 0 {
+			col = col[index+1:]
+		}
+		cols[i] = col
+	}
+
+	return strings.Join(cols, ",")
+}
+
 // 具体规则参照:http://www.sqlite.org/datatype3.html
 func (s *sqlite3) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 	if col == nil {
@@ -145,9 +255,10 @@ func (s *sqlite3) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 	case reflect.Float32, reflect.Float64:
 		buf.WriteString("REAL")
 	case reflect.Array, reflect.Slice:
-		if col.GoType.Elem().Kind() == reflect.Int8 {
-			buf.WriteString("BLOB")
+		if !isBytes(col.GoType) {
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
+		buf.WriteString("BLOB")
 	case reflect.Struct:
 		switch col.GoType {
 		case rawBytes:
@@ -162,7 +273,11 @@ func (s *sqlite3) sqlType(buf *sqlbuilder.SQLBuilder, col *orm.Column) error {
 			buf.WriteString("TEXT")
 		case timeType:
 			buf.WriteString("DATETIME")
+		default:
+			return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 		}
+	default:
+		return fmt.Errorf("sqlType:不支持的类型:[%v]", col.GoType)
 	}
 
 	return nil
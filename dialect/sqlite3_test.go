@@ -6,6 +6,7 @@ package dialect
 
 import (
 	"database/sql"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -71,4 +72,70 @@ func TestSqlite3_sqlType(t *testing.T) {
 	buf.Reset()
 	a.NotError(s.sqlType(buf, col))
 	sqltest.Equal(a, buf.String(), "INTEGER")
+
+	col.GoType = reflect.TypeOf([]byte{})
+	buf.Reset()
+	a.NotError(s.sqlType(buf, col))
+	sqltest.Equal(a, buf.String(), "BLOB")
+
+	// 不支持的类型
+	col.GoType = reflect.TypeOf([]string{})
+	a.Error(s.sqlType(buf, col))
+	col.GoType = reflect.TypeOf(map[string]int{})
+	a.Error(s.sqlType(buf, col))
+	col.GoType = reflect.TypeOf(struct{}{})
+	a.Error(s.sqlType(buf, col))
+}
+
+func TestSqlite3_Retryable(t *testing.T) {
+	a := assert.New(t)
+	s := &sqlite3{}
+
+	a.True(s.Retryable(errors.New("database is locked")))
+	a.True(s.Retryable(errors.New("database table is locked: tbl")))
+	a.False(s.Retryable(errors.New("UNIQUE constraint failed")))
+	a.False(s.Retryable(nil))
+}
+
+func TestSqlite3_LockSQL(t *testing.T) {
+	a := assert.New(t)
+	s := &sqlite3{}
+
+	query, err := s.LockSQL(&sqlbuilder.Lock{})
+	a.NotError(err).Empty(query)
+
+	query, err = s.LockSQL(&sqlbuilder.Lock{Share: true, Of: []string{"t1"}})
+	a.NotError(err).Empty(query)
+
+	_, err = s.LockSQL(&sqlbuilder.Lock{Wait: sqlbuilder.LockSkipLocked})
+	a.Equal(err, sqlbuilder.ErrLockNotSupported)
+}
+
+func TestSqlite3_TranslateError(t *testing.T) {
+	a := assert.New(t)
+	s := &sqlite3{}
+
+	err := s.TranslateError(errors.New("UNIQUE constraint failed: p_user.firstName, p_user.lastName"))
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+	ce := err.(*orm.ConstraintError)
+	a.Empty(ce.Constraint).Equal(ce.Column, "firstName,lastName")
+	a.Equal(s.TranslateError(err), err)
+
+	err = s.TranslateError(errors.New("UNIQUE constraint failed: index 'unique_name'"))
+	a.Equal(err.(*orm.ConstraintError).Constraint, "unique_name")
+
+	err = s.TranslateError(errors.New("FOREIGN KEY constraint failed"))
+	a.True(errors.Is(err, orm.ErrForeignKeyViolation))
+
+	err = s.TranslateError(errors.New("NOT NULL constraint failed: p_user.name"))
+	a.True(errors.Is(err, orm.ErrNotNullViolation))
+	a.Equal(err.(*orm.ConstraintError).Column, "name")
+
+	err = s.TranslateError(errors.New("CHECK constraint failed: chk_name"))
+	a.True(errors.Is(err, orm.ErrCheckViolation))
+	a.Equal(err.(*orm.ConstraintError).Constraint, "chk_name")
+
+	other := errors.New("database is locked")
+	a.Equal(s.TranslateError(other), other)
+	a.Nil(s.TranslateError(nil))
 }
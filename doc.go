@@ -26,6 +26,17 @@
 //  // 另一个 DB 实例
 //  db2 := orm.NewDB("sqlite3", "./db2", "db2_", dialect.Sqlite3())
 //
+//  // 指定连接池参数及健康检测
+//  db3, err := orm.NewDBWithOptions(&orm.Options{
+//      DriverName:          "mysql",
+//      DataSourceName:      "root@/db3",
+//      Dialect:             dialect.Mysql(),
+//      MaxOpenConns:        20,
+//      PingTimeout:         time.Second,
+//      HealthCheckInterval: time.Minute,
+//      HealthCheck:         func(stats sql.DBStats, err error) {...},
+//  })
+//
 //
 //
 // 占位符
@@ -43,6 +54,18 @@
 //
 //
 //
+// 命名参数
+//
+//
+// 当参数中包含 sql.NamedArg 时，语句中的 @name 和 :name 会被转换成普通的占位符，
+// 参数也会按其在语句中出现的顺序重新排列，同一个命名参数可以出现多次。
+// 所以即使驱动本身不支持命名参数，以下语句也可以在所有的数据库中执行：
+//  db.Query("select * from #user where id=@id or pid=@id", sql.Named("id", 5))
+// 引号及注释中的内容不会被转换，语句中的名称在参数中不存在，或是参数中的命名参数
+// 未被使用，均会返回错误。Prepare() 和 PrepareContext() 不会对命名参数作转换。
+//
+//
+//
 // Model:
 //
 // orm 包通过 struct tag 来描述 model 在数据库中的结构。大概格式如下：
@@ -69,6 +92,8 @@
 //  不支持该特性的数据，将会忽略该标签的内容，比如 sqlite3。
 //  NOTE:字符串类型必须指定长度，若长度过大或是将长度设置了-1，
 //  想使用类似于 TEXT 等不定长的形式表达。
+//  未指定长度的字符串，mysql 和 postgres 的 Create() 和 CreateTableSQL()
+//  会返回错误，而不是生成无效的 VARCHAR(0)；[]byte 之外的切片和数组同样会返回错误。
 //
 //  nullable(true|false): 相当于定义表结构时的 NULL，建议尽量少用该属性，
 //  若非用不可的话，与之对应的 Go 属性必须声明为 NullString之类的结构。
@@ -84,6 +109,10 @@
 //  index(index_name): 普通的关键字索引，同 unique 一样会将名称相同的索引定义为一个联合索引。
 //
 // occ(true|false) 当前列作为乐观锁字段。
+//  Update() 时未更新任何数据，表示数据已被其它操作修改，会返回 ErrOptimisticLock。
+//
+//  tenant: 当前列作为租户列，通过 DB.WithTenant() 返回的实例操作数据时，
+//  会自动以该列作为限定条件，具体可参考 DB.WithTenant()。
 //
 //  default(value): 指定默认值。相当于定义表结构时的 DEFAULT。
 //  当一个字段如果是个零值(reflect.Zero())时，将会使用它的默认值，
@@ -99,6 +128,15 @@
 //  因为 check 约束的表达式可以通过 and 或是 or 等符号连接多条基本表达式，
 //  在字段 struct tag 中指定会显得有点怪异。
 //
+// 参数中可以包含成对的括号，其中的逗号和分号不会被当作分隔符；
+// 也可以用单引号将整个参数包含起来，此时引号不属于参数的值，引号中的反斜杠用于转义其后的字符；
+// 不以引号开头的参数中，引号和反斜杠都按原样保留，比如 default(it's)：
+//  Sex  string `orm:"name(sex);len(20);default('male,female')"`
+//  func(u *User) Meta() string {
+//      return "name(user);check(chk_type, type IN (1,2))"
+//  }
+// struct tag 的格式错误时，NewModel() 会返回 *TagError，其中包含了出错的字段及位置。
+//
 //
 // model.Metaer:
 //
@@ -111,6 +149,17 @@
 //
 //
 //
+// ModelBuilder:
+//
+// 对于无法添加 struct tag 的类型，比如其它包中的类型，可以通过 ModelBuilder
+// 在代码中声明模型的结构，注册之后即可像其它模型一样使用：
+//  orm.NewModelBuilder(&other.User{}, "users").
+//      Column("ID", "id").AI("id").
+//      Column("Name", "name").Len("name", 20).
+//      Register()
+//
+//
+//
 // 约束名：
 //
 // index,unique,check,fk 都是可以指定约束名的，在表中，约束名必须是唯一的，
@@ -159,6 +208,40 @@
 //  sql = "update #tbl_name set name=? where id=?"
 //  r, err := e.Exec(sql, []interface{}{"name1", 5})
 //
+// 读写分离：
+//
+// 通过 NewDBWithReplicas() 可以构建一个包含从数据库的 DB 实例，
+// 只读操作会被分配到从数据库，写入操作及事务则在主数据库中执行：
+//  db, err := orm.NewDBWithReplicas(primary, []*orm.Replica{{DB: r1, Weight: 2}, {DB: r2}}, "prefix_", dialect.Mysql())
+//  db.Select(u)           // 从数据库
+//  db.Primary().Select(u) // 主数据库，用于读取刚写入的数据
+//
+// 统计：
+//
+// 通过 DB.WithCollector() 或是 Options.Collector 指定一个 Collector 接口，
+// 可以按语句类型收集执行时间、错误数量，以及事务和连接池的状态，
+// metrics 子包提供了一个基于内存的实现，并可以输出 Prometheus 格式的数据。
+//
+// 追踪：
+//
+// 通过 DB.WithTracer() 或是 Options.Tracer 指定一个 Tracer 接口，
+// 每条语句、每个以模型为参数的操作以及每个事务都会创建一个 span，
+// 属性名称与 OpenTelemetry 的语义约定相同，可以很方便地包装成 OpenTelemetry 的实现。
+//
+// 代码生成：
+//
+// cmd/ormaccessor 可以为模型生成实现了 Accessor 和 fetch.ScanTargeter 接口的方法，
+// 之后对这些模型的增删改查操作都不再通过反射查找字段：
+//  //go:generate ormaccessor -types User,Group -o accessor.go
+//
+// 模型检测：
+//
+// 类型无法转换、外键引用的表不存在等问题，一般要到执行 DDL 时才会发现。
+// 可以在程序启动时通过 Validate() 检测所有的模型，一次性返回所有的问题：
+//  if err := orm.Validate(dialect.Mysql(), &User{}, &Group{}); err != nil {
+//      panic(err)
+//  }
+//
 // 事务：
 //
 // 默认的 DB 是不支持事务的，若需要事务支持，则需要调用 DB.Begin()
@@ -0,0 +1,133 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 由 Dialect.TranslateError() 转换之后的错误类型，
+// 可以通过 errors.Is() 判断具体的错误类型。
+var (
+	ErrUniqueViolation     = errors.New("违反唯一约束")
+	ErrForeignKeyViolation = errors.New("违反外键约束")
+	ErrNotNullViolation    = errors.New("违反非空约束")
+	ErrCheckViolation      = errors.New("违反 check 约束")
+
+	// ErrNoRows 没有符合条件的记录
+	ErrNoRows = sql.ErrNoRows
+
+	// ErrOptimisticLock 乐观锁冲突，数据已经被其它操作修改
+	ErrOptimisticLock = errors.New("乐观锁冲突")
+)
+
+// ConstraintError 表示违反了约束的错误
+type ConstraintError struct {
+	// ErrUniqueViolation 等预定义的错误类型
+	Err error
+
+	// 约束名，与 Model.UniqueIndexes、Model.FK 和 Model.Check 中的键名相对应。
+	//
+	// 部分数据库的错误信息中并不包含约束名，比如 sqlite3 的唯一约束，
+	// 此时为空，可以通过 Column 判断。
+	Constraint string
+
+	// 相关的列名，多个列以逗号分隔，无法获取时为空。
+	Column string
+
+	// 数据库驱动返回的原始错误
+	Raw error
+}
+
+func (err *ConstraintError) Error() string {
+	msg := err.Err.Error()
+	if err.Constraint != "" {
+		msg += " " + err.Constraint
+	} else if err.Column != "" {
+		msg += " " + err.Column
+	}
+
+	return msg + ": " + err.Raw.Error()
+}
+
+// Unwrap 返回 Raw，可以通过 errors.As() 获取驱动的原始错误。
+func (err *ConstraintError) Unwrap() error {
+	return err.Raw
+}
+
+// Is 实现 errors.Is 的判断，与 Err 相同时返回 true。
+func (err *ConstraintError) Is(target error) bool {
+	return target == err.Err
+}
+
+// NotFoundError 表示 MultSelect 中部分对象未找到对应的记录
+//
+// errors.Is(err, ErrNoRows) 对其返回 true。
+type NotFoundError struct {
+	// 未找到记录的对象在参数中的索引
+	Indexes []int
+}
+
+func (err *NotFoundError) Error() string {
+	return fmt.Sprintf("以下索引的对象未找到对应的记录：%v", err.Indexes)
+}
+
+// Is 实现 errors.Is 的判断
+func (err *NotFoundError) Is(target error) bool {
+	return target == ErrNoRows
+}
+
+// TagError 表示 struct tag 或是 Metaer.Meta() 返回的内容中存在错误
+type TagError struct {
+	// 出错的字段，格式为 类型名.字段名，Metaer 中的错误则为 类型名.Meta()
+	Field string
+
+	// 完整的 struct tag 或是 Meta() 的返回值
+	Tag string
+
+	// 错误在 Tag 中的位置
+	Offset  int
+	Message string
+}
+
+func (err *TagError) Error() string {
+	return fmt.Sprintf("%s 的 tag 在位置 %d 处发生错误: %s", err.Field, err.Offset, err.Message)
+}
+
+// ValidationError 由 Validate() 返回，包含了检测到的所有问题。
+type ValidationError struct {
+	Errors []error
+}
+
+func (err *ValidationError) Error() string {
+	msgs := make([]string, 0, len(err.Errors))
+	for _, e := range err.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is 实现 errors.Is 的判断，Errors 中任意一个错误符合即返回 true。
+func (err *ValidationError) Is(target error) bool {
+	for _, e := range err.Errors {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 实现 errors.As 的判断，将 Errors 中第一个符合的错误写入 target。
+func (err *ValidationError) As(target interface{}) bool {
+	for _, e := range err.Errors {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
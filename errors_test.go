@@ -0,0 +1,92 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+func TestConstraintError(t *testing.T) {
+	a := assert.New(t)
+
+	raw := errors.New("raw")
+	err := &orm.ConstraintError{Err: orm.ErrUniqueViolation, Constraint: "unique_name", Raw: raw}
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+	a.True(errors.Is(err, raw))
+	a.False(errors.Is(err, orm.ErrCheckViolation))
+	a.Equal(err.Error(), orm.ErrUniqueViolation.Error()+" unique_name: raw")
+
+	err = &orm.ConstraintError{Err: orm.ErrNotNullViolation, Column: "name", Raw: raw}
+	a.Equal(err.Error(), orm.ErrNotNullViolation.Error()+" name: raw")
+}
+
+func TestValidationError(t *testing.T) {
+	a := assert.New(t)
+
+	raw := errors.New("raw")
+	tagErr := &orm.TagError{Field: "User.Name", Message: "tag"}
+	err := &orm.ValidationError{Errors: []error{raw, tagErr}}
+	a.True(errors.Is(err, raw))
+	a.False(errors.Is(err, orm.ErrNoRows))
+
+	var te *orm.TagError
+	a.True(errors.As(err, &te)).Equal(te, tagErr)
+	var ce *orm.ConstraintError
+	a.False(errors.As(err, &ce))
+}
+
+func TestDB_TranslateError(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	_, err := db.Insert(&modeltest.UserInfo{UID: 10, FirstName: "f1", LastName: "l1"})
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+	var ce *orm.ConstraintError
+	a.True(errors.As(err, &ce))
+
+	// 事务中
+	tx, err := db.Begin()
+	a.NotError(err)
+	_, err = tx.Exec("INSERT INTO #user_info({uid},{firstName},{lastName},{sex}) VALUES(?,?,NULL,?)", 11, "f11", "male")
+	a.True(errors.Is(err, orm.ErrNotNullViolation))
+	a.NotError(tx.Rollback())
+
+	// LastInsertID
+	_, err = db.LastInsertID(&modeltest.Group{ID: 1, Name: "g1"})
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+}
+
+func TestDB_Select_notFound(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	u := &modeltest.UserInfo{UID: 100}
+	a.Equal(db.Select(u), orm.ErrNoRows)
+	a.Empty(u.FirstName)
+
+	// 兼容之前的行为
+	a.NotError(db.IgnoreNotFound().Select(u))
+	a.Empty(u.FirstName)
+
+	tx, err := db.Begin()
+	a.NotError(err)
+	err = tx.MultSelect(&modeltest.UserInfo{UID: 1}, &modeltest.UserInfo{UID: 100}, &modeltest.UserInfo{UID: 2}, &modeltest.UserInfo{UID: 101})
+	a.True(errors.Is(err, orm.ErrNoRows))
+	var nf *orm.NotFoundError
+	a.True(errors.As(err, &nf)).Equal(nf.Indexes, []int{1, 3})
+	a.NotError(tx.MultSelect(&modeltest.UserInfo{UID: 1}, &modeltest.UserInfo{UID: 2}))
+	a.NotError(tx.Commit())
+}
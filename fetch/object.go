@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"unicode"
 
 	t "github.com/issue9/orm/internal/tags"
@@ -19,6 +20,15 @@ type AfterFetcher interface {
 	AfterFetch() error
 }
 
+// ScanTargeter 提供了不通过反射获取各列对应字段地址的方法。
+//
+// 一般由 cmd/ormaccessor 生成，Object() 在导出数据时会优先使用此接口。
+type ScanTargeter interface {
+	// 返回与 cols 中各列对应的字段地址，可直接用于 sql.Rows.Scan()。
+	// 对象中不存在的列，需要返回一个可以接受任意值的地址。
+	OrmScanTargets(cols []string) []interface{}
+}
+
 // ErrInvalidKind 表示当前功能对数据的 Kind 值有特殊需求。
 var ErrInvalidKind = errors.New("无效的 Kind 类型")
 
@@ -54,6 +64,8 @@ func Obj(obj interface{}, rows *sql.Rows) (int, error) {
 //      Count int `orm:"-"`         // 不会匹配与该字段对应的列。
 //  }
 //
+// 无法添加 struct tag 的类型，可以通过 RegisterNames() 指定字段与列的对应关系。
+//
 // 第一个参数用于表示有多少数据被正确导入到 obj 中
 func Object(rows *sql.Rows, obj interface{}) (int, error) {
 	val := reflect.ValueOf(obj)
@@ -78,6 +90,35 @@ func Object(rows *sql.Rows, obj interface{}) (int, error) {
 	}
 }
 
+// 通过 RegisterNames() 注册的字段名与列名的对应关系
+var registered = &struct {
+	sync.RWMutex
+	items map[reflect.Type]map[string]string
+}{
+	items: map[reflect.Type]map[string]string{},
+}
+
+// RegisterNames 为类型 t 注册各字段对应的列名。
+//
+// 用于无法添加 struct tag 的类型，names 的键名为字段名，键值为列名，
+// 可以包含匿名字段中的字段。注册之后，Object() 等函数只会导出 names 中指定的字段，
+// 字段的 struct tag 将被忽略。重复注册会覆盖之前的内容。
+func RegisterNames(t reflect.Type, names map[string]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	registered.Lock()
+	defer registered.Unlock()
+	registered.items[t] = names
+}
+
+func registeredNames(t reflect.Type) map[string]string {
+	registered.RLock()
+	defer registered.RUnlock()
+	return registered.items[t]
+}
+
 // 将 v 转换成 map[string]reflect.Value 形式，其中键名为对象的字段名，
 // 键值为字段的值。支持匿名字段，不会转换不可导出(小写字母开头)的
 // 字段，也不会转换 struct tag 以-开头的字段。
@@ -89,6 +130,11 @@ func parseObject(v reflect.Value, ret *map[string]reflect.Value) error {
 		return ErrInvalidKind
 	}
 
+	return parseFields(v, registeredNames(v.Type()), ret)
+}
+
+// names 为通过 RegisterNames() 注册的内容，为 nil 时表示按 struct tag 处理。
+func parseFields(v reflect.Value, names map[string]string, ret *map[string]reflect.Value) error {
 	vt := v.Type()
 	num := vt.NumField()
 	for i := 0; i < num; i++ {
@@ -96,11 +142,25 @@ func parseObject(v reflect.Value, ret *map[string]reflect.Value) error {
 		vf := v.Field(i)
 
 		if field.Anonymous {
-			parseObject(vf, ret)
+			for vf.Kind() == reflect.Ptr {
+				vf = vf.Elem()
+			}
+			if vf.Kind() != reflect.Struct {
+				continue
+			}
+
+			embedded := names // 注册的内容包含了匿名字段中的字段
+			if embedded == nil {
+				embedded = registeredNames(vf.Type())
+			}
+			parseFields(vf, embedded, ret)
 			continue
 		}
 
-		name := getName(field)
+		name, err := getName(field, names)
+		if err != nil {
+			return err
+		}
 		if name == "" {
 			continue
 		}
@@ -131,27 +191,39 @@ func parseObject(v reflect.Value, ret *map[string]reflect.Value) error {
 	return nil
 }
 
-func getName(field reflect.StructField) string {
+func getName(field reflect.StructField, names map[string]string) (string, error) {
+	if names != nil {
+		return names[field.Name], nil
+	}
+
 	tags := field.Tag.Get("orm")
 	if len(tags) > 0 { // 存在 struct tag
 		if tags[0] == '-' { // 该字段被标记为忽略
-			return ""
+			return "", nil
 		}
 
-		if name, found := t.Get(tags, "name"); found {
-			return name[0]
+		name, found, err := t.Get(tags, "name")
+		if err != nil {
+			return "", fmt.Errorf("字段 %s 的 struct tag 错误: %v", field.Name, err)
+		}
+		if found {
+			return name[0], nil
 		}
 	}
 
 	// 未指定 struct tag，则尝试直接使用字段名。
 	if unicode.IsUpper(rune(field.Name[0])) {
-		return field.Name
+		return field.Name, nil
 	}
 
-	return ""
+	return "", nil
 }
 
 func getColumns(v reflect.Value, cols []string) ([]interface{}, error) {
+	if s, ok := scanTargeter(v); ok {
+		return s.OrmScanTargets(cols), nil
+	}
+
 	ret := make([]interface{}, 0, len(cols))
 
 	items := make(map[string]reflect.Value, len(cols))
@@ -171,6 +243,24 @@ func getColumns(v reflect.Value, cols []string) ([]interface{}, error) {
 	return ret, nil
 }
 
+// 若 v 实现了 ScanTargeter，则返回该接口。
+// 值为 nil 的指针交由 parseObject() 初始化，此处不作处理。
+func scanTargeter(v reflect.Value) (ScanTargeter, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	if !v.CanAddr() {
+		return nil, false
+	}
+
+	s, ok := v.Addr().Interface().(ScanTargeter)
+	return s, ok
+}
+
 // 将 rows 中的一条记录写入到 val 中，必须保证 val 的类型为 reflect.Struct。
 // 仅供 Obj() 调用。
 func fetchOnceObj(val reflect.Value, rows *sql.Rows) (int, error) {
@@ -288,3 +378,24 @@ func afterFetch(v interface{}) error {
 
 	return nil
 }
+
+// Values 获取 obj 中与 cols 各列对应的字段值。
+//
+// 列名与字段的对应关系与 Object() 相同，若某一列在 obj 中不存在，则返回错误。
+func Values(obj interface{}, cols ...string) ([]interface{}, error) {
+	items := make(map[string]reflect.Value, len(cols))
+	if err := parseObject(reflect.ValueOf(obj), &items); err != nil {
+		return nil, err
+	}
+
+	ret := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		item, found := items[col]
+		if !found {
+			return nil, columnNotExists(col)
+		}
+		ret = append(ret, item.Interface())
+	}
+
+	return ret, nil
+}
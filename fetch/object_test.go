@@ -150,6 +150,49 @@ func TestParseObject(t *testing.T) {
 	a.Equal(1, obj.User.Group)
 }
 
+// 无法添加 struct tag 的类型，通过 RegisterNames() 指定列名。
+type registeredEmail struct {
+	Address string
+}
+
+type registeredUser struct {
+	registeredEmail
+	UserID   int64
+	UserName string
+	Ignored  string
+}
+
+func TestRegisterNames(t *testing.T) {
+	a := assert.New(t)
+
+	RegisterNames(reflect.TypeOf(&registeredUser{}), map[string]string{
+		"UserID":   "id",
+		"UserName": "name",
+		"Address":  "email",
+	})
+
+	obj := &registeredUser{}
+	mapped := map[string]reflect.Value{}
+	a.NotError(parseObject(reflect.ValueOf(obj), &mapped))
+	a.Equal(3, len(mapped), "长度不相等，导出元素为:[%v]", mapped)
+
+	mapped["id"].SetInt(5)
+	mapped["name"].SetString("name")
+	mapped["email"].SetString("email")
+	a.Equal(obj, &registeredUser{
+		registeredEmail: registeredEmail{Address: "email"},
+		UserID:          5,
+		UserName:        "name",
+	})
+
+	// 匿名字段的类型单独注册
+	RegisterNames(reflect.TypeOf(registeredEmail{}), map[string]string{"Address": "addr"})
+	mapped = map[string]reflect.Value{}
+	a.NotError(parseObject(reflect.ValueOf(&registeredEmail{}), &mapped))
+	_, found := mapped["addr"]
+	a.True(found)
+}
+
 func TestGetColumns(t *testing.T) {
 	a := assert.New(t)
 	obj := &FetchUser{}
@@ -164,6 +207,18 @@ func TestGetColumns(t *testing.T) {
 	a.Equal(len(cols), 2)
 }
 
+func TestValues(t *testing.T) {
+	a := assert.New(t)
+	obj := &FetchUser{ID: 5, Username: "u5", FetchEmail: FetchEmail{Email: "e5"}}
+
+	vals, err := Values(obj, "id", "Email", "Username")
+	a.NotError(err)
+	a.Equal(vals, []interface{}{5, "e5", "u5"})
+
+	vals, err = Values(obj, "id", "not-exists")
+	a.Error(err).Nil(vals)
+}
+
 func TestObject(t *testing.T) {
 	a := assert.New(t)
 	db := initDB(a)
@@ -334,3 +389,50 @@ func TestObjectNotFound(t *testing.T) {
 	a.Error(err).Equal(0, cnt) // 非指针传递，出错
 	a.NotError(rows.Close())
 }
+
+// 实现了 ScanTargeter 的对象，字段名与列名并不相同。
+type scanUser struct {
+	UID  int
+	Mail string
+}
+
+func (u *scanUser) OrmScanTargets(cols []string) []interface{} {
+	targets := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		switch col {
+		case "id":
+			targets = append(targets, &u.UID)
+		case "Email":
+			targets = append(targets, &u.Mail)
+		default:
+			var v interface{}
+			targets = append(targets, &v)
+		}
+	}
+	return targets
+}
+
+func TestObject_ScanTargeter(t *testing.T) {
+	a := assert.New(t)
+	db := initDB(a)
+	defer closeDB(db, a)
+
+	rows, err := db.Query(`SELECT id,Email,Username FROM user WHERE id<2 ORDER BY id`)
+	a.NotError(err).NotNil(rows)
+	var objs []*scanUser
+	cnt, err := Object(rows, &objs)
+	a.NotError(err).Equal(cnt, 2)
+	a.NotError(rows.Close())
+	a.Equal(objs, []*scanUser{
+		&scanUser{UID: 0, Mail: "email-0"},
+		&scanUser{UID: 1, Mail: "email-1"},
+	})
+
+	rows, err = db.Query(`SELECT id,Email FROM user WHERE id=1`)
+	a.NotError(err).NotNil(rows)
+	obj := &scanUser{}
+	cnt, err = Object(rows, obj)
+	a.NotError(err).Equal(cnt, 1)
+	a.NotError(rows.Close())
+	a.Equal(obj, &scanUser{UID: 1, Mail: "email-1"})
+}
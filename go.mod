@@ -1,5 +1,7 @@
 module github.com/issue9/orm
 
+go 1.16
+
 require (
 	github.com/go-sql-driver/mysql v1.4.0
 	github.com/issue9/assert v1.0.0
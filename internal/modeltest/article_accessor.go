@@ -0,0 +1,51 @@
+// 由 ormaccessor 生成，请勿手动修改。
+
+package modeltest
+
+// OrmColumns 实现 orm.Accessor 接口
+func (m *Article) OrmColumns() []string {
+	return []string{
+		"id",
+		"title",
+		"content",
+		"created",
+	}
+}
+
+// OrmValues 实现 orm.Accessor 接口
+func (m *Article) OrmValues() []interface{} {
+	return []interface{}{
+		m.ID,
+		m.Title,
+		m.Content,
+		m.Created,
+	}
+}
+
+// OrmPK 实现 orm.Accessor 接口
+func (m *Article) OrmPK() []interface{} {
+	return []interface{}{
+		m.ID,
+	}
+}
+
+// OrmScanTargets 实现 fetch.ScanTargeter 接口
+func (m *Article) OrmScanTargets(cols []string) []interface{} {
+	targets := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		switch col {
+		case "id":
+			targets = append(targets, &m.ID)
+		case "title":
+			targets = append(targets, &m.Title)
+		case "content":
+			targets = append(targets, &m.Content)
+		case "created":
+			targets = append(targets, &m.Created)
+		default:
+			var v interface{}
+			targets = append(targets, &v)
+		}
+	}
+	return targets
+}
@@ -67,3 +67,30 @@ type Account struct {
 func (m *Account) Meta() string {
 	return "name(account)"
 }
+
+// Order 带一个租户列
+type Order struct {
+	ID     int64  `orm:"name(id);pk"`
+	Tenant int64  `orm:"name(tenant_id);tenant"`
+	Title  string `orm:"name(title);len(50)"`
+}
+
+// Meta 指定表属性
+func (m *Order) Meta() string {
+	return "name(orders)"
+}
+
+// Article 通过 cmd/ormaccessor 生成了访问方法，不再通过反射访问字段
+//
+//go:generate go run ../../cmd/ormaccessor -types Article -o article_accessor.go
+type Article struct {
+	ID      int64  `orm:"name(id);ai"`
+	Title   string `orm:"name(title);len(200);unique(unique_title)"`
+	Content string `orm:"name(content);len(-1)"`
+	Created int64  `orm:"name(created)"`
+}
+
+// Meta 指定表属性
+func (m *Article) Meta() string {
+	return "name(articles)"
+}
@@ -0,0 +1,31 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package sqlnorm 将 SQL 语句标准化，以便比较两条语句是否相等。
+//
+// 由 ormtest 和 internal/sqltest 共用，不能依赖 orm 及其子包，
+// 否则 sqlbuilder 等包的测试会产生循环引用。
+package sqlnorm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	replacer           = strings.NewReplacer(")", " ) ", "(", " ( ", ",", " , ")
+	spaceReplaceRegexp = regexp.MustCompile("\\s+")
+)
+
+// Normalize 返回标准化之后的 SQL 语句，忽略大小写与多余的空格。
+func Normalize(s string) string {
+	// 将'(', ')', ',' 等字符的前后空格标准化
+	s = replacer.Replace(s)
+
+	// 转换成小写，去掉首尾空格
+	s = strings.TrimSpace(strings.ToLower(s))
+
+	// 去掉多余的空格。
+	return spaceReplaceRegexp.ReplaceAllString(s, " ")
+}
@@ -6,29 +6,12 @@
 package sqltest
 
 import (
-	"regexp"
-	"strings"
-
 	"github.com/issue9/assert"
-)
 
-var replacer = strings.NewReplacer(")", " ) ", "(", " ( ", ",", " , ")
-
-var spaceReplaceRegexp = regexp.MustCompile("\\s+")
+	"github.com/issue9/orm/internal/sqlnorm"
+)
 
 // Equal 检测两条 SQL 语句是否相等，忽略大小写与多余的空格。
 func Equal(a *assert.Assertion, s1, s2 string) {
-	// 将'(', ')', ',' 等字符的前后空格标准化
-	s1 = replacer.Replace(s1)
-	s2 = replacer.Replace(s2)
-
-	// 转换成小写，去掉首尾空格
-	s1 = strings.TrimSpace(strings.ToLower(s1))
-	s2 = strings.TrimSpace(strings.ToLower(s2))
-
-	// 去掉多余的空格。
-	s1 = spaceReplaceRegexp.ReplaceAllString(s1, " ")
-	s2 = spaceReplaceRegexp.ReplaceAllString(s2, " ")
-
-	a.Equal(s1, s2)
+	a.Equal(sqlnorm.Normalize(s1), sqlnorm.Normalize(s2))
 }
@@ -23,106 +23,293 @@
 //       "unique":nil,
 //       "fun"   :["add","1","2"]
 //  ]
+//
+// 参数中可以包含成对的括号，括号中的逗号和分号不会被当作分隔符；
+// 也可以使用单引号或是双引号将整个参数包含起来，此时参数的值为引号中的内容，
+// 引号中的反斜杠用于转义其后的字符。如：
+//  "default('a,b');check(chk_name, a IN (1,2));default('it\\'s')"
+//  // 以下将会被解析成：
+//  [
+//       "default":["a,b"],
+//       "check"  :["chk_name", "a IN (1,2)"],
+//       "default":["it's"]
+//  ]
+//
+// 只有以引号开头的参数才会被特殊处理，其它参数中的引号和反斜杠都按原样保留，
+// 比如 default(it's) 的参数为 it's，default(a\b) 的参数为 a\b。
+// 但是以引号开头的参数会去掉其引号，比如 default('abc') 的参数为 abc，
+// 而不是之前版本中的 'abc'。
 package tags
 
-import "strings"
-
-// 将第二种风格的 struct tag 转换成第一种风格的。
-var styleReplace = strings.NewReplacer("(", ",", ")", "")
+import (
+	"fmt"
+	"strings"
+)
 
 // Tag 解析后的单个标签标签内容
 type Tag struct {
-	Name string
-	Args []string
+	Name   string
+	Args   []string
+	Offset int // 该标签在原始字符串中的起始位置
 }
 
-// Parse 分析 tag 的内容，并以 map 的形式返回
-func Parse(tag string) []*Tag {
-	ret := make([]*Tag, 0, 10)
+// Error 表示解析过程中发生的错误
+type Error struct {
+	Offset  int // 错误在原始字符串中的位置
+	Message string
+}
 
-	if len(tag) == 0 {
-		return nil
+func (err *Error) Error() string {
+	return fmt.Sprintf("位置 %d: %s", err.Offset, err.Message)
+}
+
+type parser struct {
+	data string
+	pos  int
+	open int // 当前标签中左括号的位置
+}
+
+func (p *parser) error(offset int, format string, v ...interface{}) error {
+	return &Error{Offset: offset, Message: fmt.Sprintf(format, v...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
 	}
+}
 
-	if strings.IndexByte(tag, '(') > -1 {
-		tag = styleReplace.Replace(tag)
+// Parse 分析 tag 的内容，并以数组的形式返回
+func Parse(tag string) ([]*Tag, error) {
+	if len(tag) == 0 {
+		return nil, nil
 	}
 
-	parts := strings.Split(tag, ";")
-	for _, part := range parts {
-		if len(part) == 0 {
+	ret := make([]*Tag, 0, 10)
+	p := &parser{data: tag}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return ret, nil
+		}
+
+		if p.data[p.pos] == ';' { // 空的内容
+			p.pos++
 			continue
 		}
-		part = strings.Trim(part, ",")
-		items := strings.Split(part, ",")
-		ret = append(ret, &Tag{
-			Name: items[0],
-			Args: items[1:],
-		})
-	}
 
-	return ret
+		t, err := p.parseTag()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, t)
+	}
 }
 
-// Get 从 tag 中查找名称为 name 的内容。
-// 第二个参数用于判断该项是否存在。若存在多个同外的，则只返回第一个。
-func Get(tag, name string) ([]string, bool) {
-	if len(tag) == 0 {
-		return nil, false
+// 分析单个标签，包括其后的分号。
+func (p *parser) parseTag() (*Tag, error) {
+	t := &Tag{Offset: p.pos, Args: []string{}}
+
+	end := strings.IndexAny(p.data[p.pos:], "(,;")
+	if end < 0 {
+		end = len(p.data)
+	} else {
+		end += p.pos
 	}
+	t.Name = strings.TrimSpace(p.data[p.pos:end])
+	if t.Name == "" {
+		return nil, p.error(p.pos, "缺少名称")
+	}
+	p.pos = end
 
-	if strings.IndexByte(tag, '(') > -1 {
-		tag = styleReplace.Replace(tag)
+	if p.pos >= len(p.data) {
+		return t, nil
 	}
 
-	parts := strings.Split(tag, ";")
-	for _, part := range parts {
-		if len(part) == 0 {
-			continue
+	var err error
+	switch p.data[p.pos] {
+	case ';':
+		p.pos++
+	case ',': // name,arg1,arg2
+		p.pos++
+		if t.Args, err = p.parseArgs(false); err != nil {
+			return nil, err
+		}
+
+		// 兼容 name,arg1, 这种以逗号结尾的写法
+		if last := len(t.Args) - 1; last >= 0 && t.Args[last] == "" {
+			t.Args = t.Args[:last]
+		}
+	case '(': // name(arg1,arg2)
+		p.open = p.pos
+		p.pos++
+		if p.skipSpace(); p.pos < len(p.data) && p.data[p.pos] == ')' { // name()
+			p.pos++
+		} else if t.Args, err = p.parseArgs(true); err != nil {
+			return nil, err
+		} else if p.data[p.pos-1] != ')' {
+			return nil, p.error(p.open, "括号未闭合")
 		}
 
-		part = strings.Trim(part, ",")
-		items := strings.Split(part, ",")
-		if items[0] == name {
-			return items[1:], true
+		p.skipSpace()
+		if p.pos < len(p.data) {
+			if p.data[p.pos] != ';' {
+				return nil, p.error(p.pos, "%s 之后包含了多余的内容", t.Name)
+			}
+			p.pos++
 		}
 	}
 
-	return nil, false
+	return t, nil
 }
 
-// MustGet 功能同 Get() 函数，但在无法找到的情况下，会返回 defVal 做为默认值。
-func MustGet(tag, name string, defVal ...string) []string {
-	if ret, found := Get(tag, name); found {
-		return ret
-	}
+// 分析参数列表，同时会跳过参数列表的结束符。
+//
+// paren 表示参数是否包含在括号中，为 true 时以右括号结束，否则以分号结束。
+func (p *parser) parseArgs(paren bool) ([]string, error) {
+	args := make([]string, 0, 5)
+	for {
+		arg, err := p.parseArg(paren)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
 
-	return defVal
+		if p.pos >= len(p.data) {
+			return args, nil
+		}
+
+		c := p.data[p.pos]
+		p.pos++
+		if c != ',' { // 右括号或是分号
+			return args, nil
+		}
+	}
 }
 
-// Has 查询指定名称的项是否存在，若只是查找是否存在该项，
-// 使用 Has() 会比 Get() 要快上许多。
-func Has(tag, name string) bool {
-	if len(tag) == 0 {
-		return false
+// 分析单个参数，不包含其后的分隔符。
+func (p *parser) parseArg(paren bool) (string, error) {
+	p.skipSpace()
+	start := p.pos
+
+	if p.pos < len(p.data) && (p.data[p.pos] == '\'' || p.data[p.pos] == '"') {
+		val, err := p.parseQuoted()
+		if err != nil {
+			return "", err
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] == ',' ||
+			(paren && p.data[p.pos] == ')') || (!paren && p.data[p.pos] == ';') {
+			return val, nil
+		}
+
+		// 引号之后还有其它内容，比如 'a' || 'b'，当作普通的参数处理。
+		p.pos = start
 	}
 
-	if strings.IndexByte(tag, '(') > -1 {
-		tag = styleReplace.Replace(tag)
+	var buf strings.Builder
+	parens := make([]int, 0, 2) // 未闭合的左括号的位置
+LOOP:
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch c {
+		case '(':
+			parens = append(parens, p.pos)
+		case ')':
+			if len(parens) == 0 {
+				if paren {
+					break LOOP
+				}
+				return "", p.error(p.pos, "多余的右括号")
+			}
+			parens = parens[:len(parens)-1]
+		case ',':
+			if len(parens) == 0 {
+				break LOOP
+			}
+		case ';':
+			if len(parens) == 0 && !paren {
+				break LOOP
+			}
+		}
+
+		buf.WriteByte(c)
+		p.pos++
 	}
 
-	parts := strings.Split(tag, ";")
-	for _, part := range parts {
-		if len(part) == 0 {
-			continue
+	if len(parens) > 0 {
+		return "", p.error(parens[len(parens)-1], "括号未闭合")
+	}
+
+	if paren && p.pos >= len(p.data) {
+		return "", p.error(p.open, "括号未闭合")
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// 分析以引号包含的字符串，返回去掉引号并处理转义之后的内容。
+func (p *parser) parseQuoted() (string, error) {
+	quote := p.data[p.pos]
+	start := p.pos
+	p.pos++
+
+	var buf strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '\\':
+			if p.pos+1 >= len(p.data) {
+				return "", p.error(p.pos, "转义字符之后缺少内容")
+			}
+			buf.WriteByte(p.data[p.pos+1])
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return buf.String(), nil
+		default:
+			buf.WriteByte(c)
+			p.pos++
 		}
+	}
+
+	return "", p.error(start, "引号未闭合")
+}
+
+// Get 从 tag 中查找名称为 name 的内容。
+// 第二个参数用于判断该项是否存在。若存在多个同外的，则只返回第一个。
+// tag 格式错误时，返回 Parse() 的错误。
+func Get(tag, name string) ([]string, bool, error) {
+	tags, err := Parse(tag)
+	if err != nil {
+		return nil, false, err
+	}
 
-		part = strings.Trim(part, ",")
-		items := strings.SplitN(part, ",", 2)
-		if items[0] == name {
-			return true
+	for _, t := range tags {
+		if t.Name == name {
+			return t.Args, true, nil
 		}
 	}
 
-	return false
+	return nil, false, nil
+}
+
+// MustGet 功能同 Get() 函数，但在无法找到的情况下，会返回 defVal 做为默认值。
+func MustGet(tag, name string, defVal ...string) ([]string, error) {
+	ret, found, err := Get(tag, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return ret, nil
+	}
+	return defVal, nil
+}
+
+// Has 查询指定名称的项是否存在
+func Has(tag, name string) (bool, error) {
+	_, found, err := Get(tag, name)
+	return found, err
 }
@@ -24,16 +24,19 @@ var tests = []*testData{
 				Args: []string{"abc"},
 			},
 			&Tag{
-				Name: "name2",
-				Args: []string{},
+				Name:   "name2",
+				Args:   []string{},
+				Offset: 9,
 			},
 			&Tag{
-				Name: "name3",
-				Args: []string{"n1", "n2"},
+				Name:   "name3",
+				Args:   []string{"n1", "n2"},
+				Offset: 17,
 			},
 			&Tag{
-				Name: "name3",
-				Args: []string{"n3", "n4"},
+				Name:   "name3",
+				Args:   []string{"n3", "n4"},
+				Offset: 29,
 			},
 		},
 	},
@@ -45,16 +48,19 @@ var tests = []*testData{
 				Args: []string{"abc"},
 			},
 			&Tag{
-				Name: "name2",
-				Args: []string{},
+				Name:   "name2",
+				Args:   []string{},
+				Offset: 10,
 			},
 			&Tag{
-				Name: "name3",
-				Args: []string{"n1", "n2"},
+				Name:   "name3",
+				Args:   []string{"n1", "n2"},
+				Offset: 18,
 			},
 			&Tag{
-				Name: "name3",
-				Args: []string{"n3", "n4"},
+				Name:   "name3",
+				Args:   []string{"n3", "n4"},
+				Offset: 31,
 			},
 		},
 	},
@@ -68,18 +74,27 @@ var tests = []*testData{
 	},
 }
 
+// 两种风格的 struct tag 解析结果相同
 func TestReplace(t *testing.T) {
-	tag1 := "name,abc;name2,;;name3,n1,n2;name3,n1,n2"
-	tag2 := "name(abc);name2,;;name3(n1,n2);name3(n1,n2)"
-	tag := styleReplace.Replace(tag2)
-	assert.Equal(t, tag, tag1)
+	a := assert.New(t)
+
+	tags1, err := Parse("name,abc;name2,;;name3,n1,n2;name3,n1,n2")
+	a.NotError(err)
+	tags2, err := Parse("name(abc);name2,;;name3(n1,n2);name3(n1,n2)")
+	a.NotError(err)
+
+	a.Equal(len(tags1), len(tags2))
+	for i, t1 := range tags1 {
+		a.Equal(t1.Name, tags2[i].Name).Equal(t1.Args, tags2[i].Args)
+	}
 }
 
 func TestParse(t *testing.T) {
 	a := assert.New(t)
 
 	for _, test := range tests {
-		m := Parse(test.tag)
+		m, err := Parse(test.tag)
+		a.NotError(err)
 		if m != nil {
 			for index, item := range m {
 				a.Equal(item, test.data[index])
@@ -94,18 +109,22 @@ func TestGet(t *testing.T) {
 	for _, test := range tests {
 		for _, items := range test.data {
 			t.Log(test.tag)
-			val, found := Get(test.tag, items.Name)
-			a.True(found)
+			val, found, err := Get(test.tag, items.Name)
+			a.NotError(err).True(found)
 			if items.Name == "name3" {
 				a.Equal(val, []string{"n1", "n2"}) // 多个重名的，只返回第一个数据
 			} else {
 				a.Equal(val, items.Args)
 			}
 
-			val, found = Get(test.tag, items.Name+"-temp")
-			a.False(found).Nil(val)
+			val, found, err = Get(test.tag, items.Name+"-temp")
+			a.NotError(err).False(found).Nil(val)
 		}
 	}
+
+	// 格式错误
+	val, found, err := Get("name(abc", "name")
+	a.Error(err).False(found).Nil(val)
 }
 
 func TestMustGet(t *testing.T) {
@@ -113,17 +132,21 @@ func TestMustGet(t *testing.T) {
 
 	for _, test := range tests {
 		for _, items := range test.data {
-			val := MustGet(test.tag, items.Name, "default")
+			val, err := MustGet(test.tag, items.Name, "default")
+			a.NotError(err)
 			if items.Name == "name3" {
 				a.Equal(val, []string{"n1", "n2"}) // 多个重名的，只返回第一个数据
 			} else {
 				a.Equal(val, items.Args)
 			}
 
-			val = MustGet(test.tag, items.Name+"-temp", "def1", "def2")
-			a.Equal(val, []string{"def1", "def2"})
+			val, err = MustGet(test.tag, items.Name+"-temp", "def1", "def2")
+			a.NotError(err).Equal(val, []string{"def1", "def2"})
 		}
 	}
+
+	val, err := MustGet("name(abc", "name", "def")
+	a.Error(err).Nil(val)
 }
 
 func TestHas(t *testing.T) {
@@ -131,9 +154,60 @@ func TestHas(t *testing.T) {
 
 	for _, test := range tests {
 		for _, item := range test.data {
-			a.True(Has(test.tag, item.Name))
+			found, err := Has(test.tag, item.Name)
+			a.NotError(err).True(found)
 
-			a.False(Has(test.tag, item.Name+"-temp"))
+			found, err = Has(test.tag, item.Name+"-temp")
+			a.NotError(err).False(found)
 		}
 	}
+
+	found, err := Has("name(abc", "name")
+	a.Error(err).False(found)
+}
+
+func TestParse_quote(t *testing.T) {
+	a := assert.New(t)
+
+	eq := func(tag string, args ...[]string) {
+		tags, err := Parse(tag)
+		a.NotError(err).Equal(len(tags), len(args))
+		for i, t := range tags {
+			a.Equal(t.Args, args[i], "%s 的第 %d 项不相等", tag, i)
+		}
+	}
+
+	eq("default('a,b')", []string{"a,b"})
+	eq(`default("a;b")`, []string{"a;b"})
+	eq(`default('it\'s')`, []string{"it's"})
+	eq(`default(it's);len(5)`, []string{"it's"}, []string{"5"})
+	eq(`default(a\b)`, []string{`a\b`})
+	eq("default( 'a' , b )", []string{"a", "b"})
+	eq("check(chk_name, a IN (1,2));name(t)", []string{"chk_name", "a IN (1,2)"}, []string{"t"})
+	eq("check(chk_name, (a>0) AND (b;c))", []string{"chk_name", "(a>0) AND (b;c)"})
+	eq("check(chk_name, name<>'a')", []string{"chk_name", "name<>'a'"})
+	eq("check(chk_name, 'a' || 'b')", []string{"chk_name", "'a' || 'b'"})
+	eq("default('');ai()", []string{""}, []string{})
+	eq("name,'a,b',c;ai", []string{"a,b", "c"}, []string{})
+}
+
+func TestParse_error(t *testing.T) {
+	a := assert.New(t)
+
+	err := func(tag string, offset int) {
+		tags, err := Parse(tag)
+		a.Error(err).Nil(tags)
+
+		e, ok := err.(*Error)
+		a.True(ok).Equal(e.Offset, offset, "%s 的错误位置 %d 与 %d 不相等", tag, e.Offset, offset)
+	}
+
+	err("name(abc", 4)
+	err("name(abc;len(5)", 4)
+	err("ai;check(chk, a IN (1,2)", 8)
+	err("check(chk, a IN (1,2)))", 22)
+	err("default('abc)", 8)
+	err("name(abc)len(5)", 9)
+	err("ai;(abc)", 3)
+	err("name,a)", 6)
 }
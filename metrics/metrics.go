@@ -0,0 +1,249 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package metrics 提供了一个基于内存的 orm.Collector 实现。
+//
+// 统计数据可以通过 Snapshot() 获取，或是通过 WriteTo()
+// 以 Prometheus 文本格式输出：
+//  m := metrics.New()
+//  db = db.WithCollector(m)
+//  http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//      db.CollectStats()
+//      m.WriteTo(w)
+//  })
+package metrics
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/issue9/orm"
+)
+
+// DefaultBuckets 默认的语句执行时间分布区间，单位为秒。
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+// Histogram 语句执行时间的分布
+type Histogram struct {
+	// 各个区间的上限，单位为秒，按从小到大排列。
+	Buckets []float64
+
+	// 执行时间小于等于对应区间上限的语句数量，与 Buckets 一一对应，
+	// 与 Prometheus 相同，为累计值。
+	Counts []uint64
+
+	Count uint64  // 语句的总数
+	Sum   float64 // 总的执行时间，单位为秒
+}
+
+// Snapshot 某一时刻的统计数据
+type Snapshot struct {
+	// 各类语句的执行时间分布
+	Statements map[orm.StmtKind]*Histogram
+
+	// 各类语句的错误数量，键名依次为语句类型和错误分类
+	Errors map[orm.StmtKind]map[string]uint64
+
+	OpenTx    int64  // 当前未结束的事务数量
+	Commits   uint64 // 提交的事务数量
+	Rollbacks uint64 // 回滚或是提交失败的事务数量
+
+	// 最后一次收集到的连接池状态
+	DBStats sql.DBStats
+}
+
+// Memory 将统计数据保存在内存中的 orm.Collector 实现
+type Memory struct {
+	mu      sync.Mutex
+	buckets []float64
+	data    *Snapshot
+}
+
+// New 声明一个新的 Memory 实例
+//
+// buckets 为执行时间的分布区间，单位为秒，为空表示使用 DefaultBuckets。
+func New(buckets ...float64) *Memory {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	return &Memory{
+		buckets: buckets,
+		data: &Snapshot{
+			Statements: make(map[orm.StmtKind]*Histogram, 6),
+			Errors:     make(map[orm.StmtKind]map[string]uint64, 6),
+		},
+	}
+}
+
+// Statement 实现 orm.Collector.Statement()
+func (m *Memory) Statement(kind orm.StmtKind, dur time.Duration, class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, found := m.data.Statements[kind]
+	if !found {
+		h = &Histogram{Buckets: m.buckets, Counts: make([]uint64, len(m.buckets))}
+		m.data.Statements[kind] = h
+	}
+
+	sec := dur.Seconds()
+	for i, b := range h.Buckets {
+		if sec <= b {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += sec
+
+	if class == "" {
+		return
+	}
+
+	errs, found := m.data.Errors[kind]
+	if !found {
+		errs = make(map[string]uint64, 5)
+		m.data.Errors[kind] = errs
+	}
+	errs[class]++
+}
+
+// TxBegin 实现 orm.Collector.TxBegin()
+func (m *Memory) TxBegin() {
+	m.mu.Lock()
+	m.data.OpenTx++
+	m.mu.Unlock()
+}
+
+// TxEnd 实现 orm.Collector.TxEnd()
+func (m *Memory) TxEnd(committed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.OpenTx--
+	if committed {
+		m.data.Commits++
+	} else {
+		m.data.Rollbacks++
+	}
+}
+
+// DBStats 实现 orm.Collector.DBStats()
+func (m *Memory) DBStats(stats sql.DBStats) {
+	m.mu.Lock()
+	m.data.DBStats = stats
+	m.mu.Unlock()
+}
+
+// Snapshot 返回当前统计数据的副本
+func (m *Memory) Snapshot() *Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := *m.data
+	s.Statements = make(map[orm.StmtKind]*Histogram, len(m.data.Statements))
+	for kind, h := range m.data.Statements {
+		hh := *h
+		hh.Counts = append([]uint64{}, h.Counts...)
+		s.Statements[kind] = &hh
+	}
+
+	s.Errors = make(map[orm.StmtKind]map[string]uint64, len(m.data.Errors))
+	for kind, errs := range m.data.Errors {
+		ee := make(map[string]uint64, len(errs))
+		for class, cnt := range errs {
+			ee[class] = cnt
+		}
+		s.Errors[kind] = ee
+	}
+
+	return &s
+}
+
+// WriteTo 以 Prometheus 文本格式输出统计数据
+func (m *Memory) WriteTo(w io.Writer) (int64, error) {
+	s := m.Snapshot()
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	kinds := make([]string, 0, len(s.Statements))
+	for kind := range s.Statements {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	cw.printf("# TYPE orm_statement_duration_seconds histogram\n")
+	for _, kind := range kinds {
+		h := s.Statements[orm.StmtKind(kind)]
+		for i, b := range h.Buckets {
+			cw.printf("orm_statement_duration_seconds_bucket{kind=%q,le=%q} %d\n", kind, formatFloat(b), h.Counts[i])
+		}
+		cw.printf("orm_statement_duration_seconds_bucket{kind=%q,le=\"+Inf\"} %d\n", kind, h.Count)
+		cw.printf("orm_statement_duration_seconds_sum{kind=%q} %s\n", kind, formatFloat(h.Sum))
+		cw.printf("orm_statement_duration_seconds_count{kind=%q} %d\n", kind, h.Count)
+	}
+
+	cw.printf("# TYPE orm_statement_errors_total counter\n")
+	for _, kind := range kinds {
+		errs := s.Errors[orm.StmtKind(kind)]
+		classes := make([]string, 0, len(errs))
+		for class := range errs {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+
+		for _, class := range classes {
+			cw.printf("orm_statement_errors_total{kind=%q,class=%q} %d\n", kind, class, errs[class])
+		}
+	}
+
+	cw.printf("# TYPE orm_open_transactions gauge\n")
+	cw.printf("orm_open_transactions %d\n", s.OpenTx)
+	cw.printf("# TYPE orm_transactions_total counter\n")
+	cw.printf("orm_transactions_total{result=\"commit\"} %d\n", s.Commits)
+	cw.printf("orm_transactions_total{result=\"rollback\"} %d\n", s.Rollbacks)
+
+	cw.printf("# TYPE orm_db_max_open_connections gauge\n")
+	cw.printf("orm_db_max_open_connections %d\n", s.DBStats.MaxOpenConnections)
+	cw.printf("# TYPE orm_db_connections gauge\n")
+	cw.printf("orm_db_connections{state=\"in_use\"} %d\n", s.DBStats.InUse)
+	cw.printf("orm_db_connections{state=\"idle\"} %d\n", s.DBStats.Idle)
+	cw.printf("# TYPE orm_db_wait_total counter\n")
+	cw.printf("orm_db_wait_total %d\n", s.DBStats.WaitCount)
+	cw.printf("# TYPE orm_db_wait_duration_seconds_total counter\n")
+	cw.printf("orm_db_wait_duration_seconds_total %s\n", formatFloat(s.DBStats.WaitDuration.Seconds()))
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// 记录写入的字节数以及第一个错误
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countWriter) printf(format string, v ...interface{}) {
+	if w.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(w.w, format, v...)
+	w.n += int64(n)
+	w.err = err
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var _ orm.Collector = &Memory{}
+
+const testDBFile = "./test.db"
+
+func TestMemory(t *testing.T) {
+	a := assert.New(t)
+
+	m := New(0.1, 0.01, 1)
+	a.Equal(m.buckets, []float64{0.01, 0.1, 1})
+
+	m.Statement(orm.StmtSelect, 5*time.Millisecond, "")
+	m.Statement(orm.StmtSelect, 50*time.Millisecond, "no_rows")
+	m.Statement(orm.StmtSelect, 2*time.Second, "")
+	m.Statement(orm.StmtInsert, time.Millisecond, "unique_violation")
+	m.TxBegin()
+	m.TxBegin()
+	m.TxEnd(true)
+	m.DBStats(sql.DBStats{MaxOpenConnections: 5, InUse: 1})
+
+	s := m.Snapshot()
+	h := s.Statements[orm.StmtSelect]
+	a.Equal(h.Counts, []uint64{1, 2, 2}).Equal(h.Count, 3)
+	a.Equal(s.Errors[orm.StmtSelect]["no_rows"], 1)
+	a.Equal(s.Errors[orm.StmtInsert]["unique_violation"], 1)
+	a.Equal(s.OpenTx, 1).Equal(s.Commits, 1).Equal(s.Rollbacks, 0)
+	a.Equal(s.DBStats.MaxOpenConnections, 5)
+
+	// Snapshot 返回的是副本
+	h.Counts[0] = 100
+	a.Equal(m.Snapshot().Statements[orm.StmtSelect].Counts[0], 1)
+
+	buf := new(bytes.Buffer)
+	n, err := m.WriteTo(buf)
+	a.NotError(err).Equal(n, buf.Len())
+	out := buf.String()
+	a.True(strings.Contains(out, `orm_statement_duration_seconds_bucket{kind="select",le="0.1"} 2`))
+	a.True(strings.Contains(out, `orm_statement_duration_seconds_bucket{kind="select",le="+Inf"} 3`))
+	a.True(strings.Contains(out, `orm_statement_errors_total{kind="insert",class="unique_violation"} 1`))
+	a.True(strings.Contains(out, "orm_open_transactions 1\n"))
+	a.True(strings.Contains(out, `orm_db_connections{state="in_use"} 1`))
+}
+
+type user struct {
+	ID   int64  `orm:"name(id);pk"`
+	Name string `orm:"name(name);len(20);unique(unique_name)"`
+}
+
+func (u *user) Meta() string {
+	return "name(users)"
+}
+
+func TestMemory_DB(t *testing.T) {
+	a := assert.New(t)
+
+	m := New()
+	db, err := orm.NewDBWithOptions(&orm.Options{
+		DriverName:     "sqlite3",
+		DataSourceName: testDBFile,
+		Dialect:        dialect.Sqlite3(),
+		Collector:      m,
+	})
+	a.NotError(err)
+	defer func() {
+		a.NotError(db.Close())
+		a.NotError(os.Remove(testDBFile))
+	}()
+
+	a.NotError(db.Create(&user{}))
+	_, err = db.Insert(&user{ID: 1, Name: "n1"})
+	a.NotError(err)
+	_, err = db.Insert(&user{ID: 2, Name: "n1"})
+	a.Error(err)
+	a.Equal(db.Select(&user{ID: 100}), orm.ErrNoRows)
+
+	tx, err := db.Begin()
+	a.NotError(err)
+	_, err = tx.Update(&user{ID: 1, Name: "n2"})
+	a.NotError(err)
+	a.NotError(tx.Rollback())
+	a.Error(tx.Rollback()) // 重复回滚不会被统计
+
+	db.CollectStats()
+
+	s := m.Snapshot()
+	a.Equal(s.Statements[orm.StmtDDL].Count, 1)
+	a.Equal(s.Statements[orm.StmtInsert].Count, 2)
+	a.Equal(s.Errors[orm.StmtInsert]["unique_violation"], 1)
+	a.Equal(s.Statements[orm.StmtSelect].Count, 1)
+	a.Equal(s.Statements[orm.StmtUpdate].Count, 1)
+	a.Equal(s.OpenTx, 0).Equal(s.Rollbacks, 1)
+	a.True(s.DBStats.OpenConnections > 0)
+}
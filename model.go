@@ -5,6 +5,7 @@
 package orm
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -28,9 +29,11 @@ const (
 // model 缓存
 var models = &struct {
 	sync.Mutex
-	items map[reflect.Type]*Model
+	items      map[reflect.Type]*Model
+	registered map[reflect.Type]*Model // 通过 ModelBuilder 注册的模型
 }{
-	items: map[reflect.Type]*Model{},
+	items:      map[reflect.Type]*Model{},
+	registered: map[reflect.Type]*Model{},
 }
 
 type conType int8
@@ -45,14 +48,28 @@ type Model struct {
 	PK            []*Column              // 主键
 	AI            *Column                // 自增列
 	OCC           *Column                // 乐观锁
+	Tenant        *Column                // 租户列
 	Check         map[string]string      // Check 键名为约束名，键值为约束表达式
 	Meta          map[string][]string    // 表级别的数据，如存储引擎，表名和字符集等。
 
 	constraints map[string]conType // 约束名缓存
+	accessor    map[string]int     // Accessor.OrmColumns() 中各列的位置
 }
 
-func propertyError(field, name, message string) error {
-	return fmt.Errorf("%s 的 %s 属性发生以下错误: %s", field, name, message)
+// 将分析 tag 时返回的错误转换成 *TagError
+//
+// t 为出错的标签，若是 tags.Parse() 返回的错误则为 nil。
+func tagError(field, tag string, t *tags.Tag, err error) error {
+	if e, ok := err.(*tags.Error); ok {
+		return &TagError{Field: field, Tag: tag, Offset: e.Offset, Message: e.Message}
+	}
+
+	return &TagError{
+		Field:   field,
+		Tag:     tag,
+		Offset:  t.Offset,
+		Message: fmt.Sprintf("%s 属性: %v", t.Name, err),
+	}
 }
 
 func (t conType) String() string {
@@ -74,6 +91,9 @@ func (t conType) String() string {
 
 // NewModel 从一个 obj 声明一个 Model 实例。
 // obj 可以是一个 struct 实例或是指针。
+//
+// 若 obj 的类型已经通过 ModelBuilder 注册，则直接返回注册的模型，
+// 否则从 struct tag 和 Metaer 接口中分析模型的结构。
 func NewModel(obj interface{}) (*Model, error) {
 	rval := reflect.ValueOf(obj)
 	for rval.Kind() == reflect.Ptr {
@@ -92,17 +112,11 @@ func NewModel(obj interface{}) (*Model, error) {
 		return m, nil
 	}
 
-	m := &Model{
-		Cols:          map[string]*Column{},
-		KeyIndexes:    map[string][]*Column{},
-		UniqueIndexes: map[string][]*Column{},
-		Name:          rtype.Name(),
-		FK:            map[string]*ForeignKey{},
-		Check:         map[string]string{},
-		Meta:          map[string][]string{},
-		constraints:   map[string]conType{},
+	if m, found := models.registered[rtype]; found {
+		return m, nil
 	}
 
+	m := newModel(rtype.Name())
 	if err := m.parseColumns(rval); err != nil {
 		return nil, err
 	}
@@ -113,10 +127,30 @@ func NewModel(obj interface{}) (*Model, error) {
 		}
 	}
 
+	index, err := accessorIndex(m, rtype)
+	if err != nil {
+		return nil, err
+	}
+	m.accessor = index
+
 	models.items[rtype] = m
 	return m, nil
 }
 
+// 声明一个空的 Model 实例
+func newModel(name string) *Model {
+	return &Model{
+		Cols:          map[string]*Column{},
+		KeyIndexes:    map[string][]*Column{},
+		UniqueIndexes: map[string][]*Column{},
+		Name:          name,
+		FK:            map[string]*ForeignKey{},
+		Check:         map[string]string{},
+		Meta:          map[string][]string{},
+		constraints:   map[string]conType{},
+	}
+}
+
 // 将 rval 中的结构解析到 m 中。支持匿名字段
 func (m *Model) parseColumns(rval reflect.Value) error {
 	rtype := rval.Type()
@@ -125,7 +159,9 @@ func (m *Model) parseColumns(rval reflect.Value) error {
 		field := rtype.Field(i)
 
 		if field.Anonymous {
-			m.parseColumns(rval.Field(i))
+			if err := m.parseColumns(rval.Field(i)); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -154,38 +190,46 @@ func (m *Model) parseColumn(col *Column, tag string) (err error) {
 		return nil
 	}
 
-	tags := tags.Parse(tag)
-	for _, tag := range tags {
-		switch tag.Name {
+	field := m.Name + "." + col.GoName
+	ts, err := tags.Parse(tag)
+	if err != nil {
+		return tagError(field, tag, nil, err)
+	}
+
+	for _, t := range ts {
+		switch t.Name {
 		case "name": // name(colname)
-			if len(tag.Args) != 1 {
-				return propertyError(col.Name, "name", "过多的参数值")
+			if len(t.Args) != 1 {
+				err = errors.New("过多的参数值")
+			} else {
+				col.Name = t.Args[0]
 			}
-			col.Name = tag.Args[0]
 		case "index":
-			err = m.setIndex(col, tag.Args)
+			err = m.setIndex(col, t.Args)
 		case "pk":
-			err = m.setPK(col, tag.Args)
+			err = m.setPK(col, t.Args)
 		case "unique":
-			err = m.setUnique(col, tag.Args)
+			err = m.setUnique(col, t.Args)
 		case "nullable":
-			err = col.setNullable(tag.Args)
+			err = col.setNullable(t.Args)
 		case "ai":
-			err = m.setAI(col, tag.Args)
+			err = m.setAI(col, t.Args)
 		case "len":
-			err = col.setLen(tag.Args)
+			err = col.setLen(t.Args)
 		case "fk":
-			err = m.setFK(col, tag.Args)
+			err = m.setFK(col, t.Args)
 		case "default":
-			err = m.setDefault(col, tag.Args)
+			err = m.setDefault(col, t.Args)
 		case "occ":
-			err = m.setOCC(col, tag.Args)
+			err = m.setOCC(col, t.Args)
+		case "tenant":
+			err = m.setTenant(col, t.Args)
 		default:
-			err = propertyError(col.Name, tag.Name, "未知的属性")
+			err = errors.New("未知的属性")
 		}
 
 		if err != nil {
-			return err
+			return tagError(field, tag, t, err)
 		}
 	}
 	// col.Name 可能在上面的 for 循环中被更改，所以要在最后再添加到 m.Cols 中
@@ -196,38 +240,47 @@ func (m *Model) parseColumn(col *Column, tag string) (err error) {
 
 // 分析 meta 接口数据。
 func (m *Model) parseMeta(tag string) error {
-	tags := tags.Parse(tag)
-	if len(tags) == 0 {
-		return nil
+	field := m.Name + ".Meta()"
+	ts, err := tags.Parse(tag)
+	if err != nil {
+		return tagError(field, tag, nil, err)
 	}
 
-	for _, v := range tags {
-		switch v.Name {
-		case "name":
-			if len(v.Args) != 1 {
-				return propertyError("Metaer", "name", "太多的值")
-			}
+	for _, t := range ts {
+		if err := m.setMeta(t); err != nil {
+			return tagError(field, tag, t, err)
+		}
+	}
 
-			m.Name = v.Args[0]
-		case "check":
-			if len(v.Args) != 2 {
-				return propertyError("Metaer", "check", "参数个数不正确")
-			}
+	return nil
+}
 
-			if _, found := m.Check[v.Args[0]]; found {
-				return propertyError("Metaer", "check", "已经存在相同名称的 check 约束")
-			}
+func (m *Model) setMeta(t *tags.Tag) error {
+	switch t.Name {
+	case "name":
+		if len(t.Args) != 1 {
+			return errors.New("太多的值")
+		}
 
-			if typ := m.hasConstraint(v.Args[0], check); typ != none {
-				return propertyError("Metaer", "check", "与其它约束名称相同")
-			}
+		m.Name = t.Args[0]
+	case "check":
+		if len(t.Args) != 2 {
+			return errors.New("参数个数不正确")
+		}
 
-			name := strings.ToLower(v.Args[0])
-			m.constraints[name] = check
-			m.Check[name] = v.Args[1]
-		default:
-			m.Meta[v.Name] = v.Args
+		if _, found := m.Check[t.Args[0]]; found {
+			return errors.New("已经存在相同名称的 check 约束")
+		}
+
+		if typ := m.hasConstraint(t.Args[0], check); typ != none {
+			return errors.New("与其它约束名称相同")
 		}
+
+		name := strings.ToLower(t.Args[0])
+		m.constraints[name] = check
+		m.Check[name] = t.Args[1]
+	default:
+		m.Meta[t.Name] = t.Args
 	}
 
 	return nil
@@ -236,18 +289,18 @@ func (m *Model) parseMeta(tag string) error {
 // occ(true) or occ
 func (m *Model) setOCC(c *Column, vals []string) error {
 	if c.IsAI() || c.Nullable {
-		return propertyError(c.Name, "occ", "自增列和允许为空的列不能作为乐观锁列")
+		return errors.New("自增列和允许为空的列不能作为乐观锁列")
 	}
 
 	if m.OCC != nil {
-		return propertyError(c.Name, "occ", "已经指定了一个乐观锁")
+		return errors.New("已经指定了一个乐观锁")
 	}
 
 	switch c.GoType.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 	default:
-		return propertyError(c.Name, "occ", "值只能是数值")
+		return errors.New("值只能是数值")
 	}
 
 	switch len(vals) {
@@ -262,24 +315,42 @@ func (m *Model) setOCC(c *Column, vals []string) error {
 			m.OCC = c
 		}
 	default:
-		return propertyError(c.Name, "occ", "指定了太多的值")
+		return errors.New("指定了太多的值")
+	}
+
+	return nil
+}
+
+// tenant
+func (m *Model) setTenant(c *Column, vals []string) error {
+	if len(vals) != 0 {
+		return errors.New("太多的值")
+	}
+
+	if c.IsAI() || c.Nullable {
+		return errors.New("自增列和允许为空的列不能作为租户列")
 	}
 
+	if m.Tenant != nil {
+		return errors.New("已经指定了一个租户列")
+	}
+
+	m.Tenant = c
 	return nil
 }
 
 // default(5)
 func (m *Model) setDefault(col *Column, vals []string) error {
 	if m.AI == col {
-		return propertyError(col.Name, "default", "自增列不能设置默认值")
+		return errors.New("自增列不能设置默认值")
 	}
 
 	if len(m.PK) == 1 && m.PK[0] == col {
-		return propertyError(col.Name, "default", "不能为主键设置默认值")
+		return errors.New("不能为主键设置默认值")
 	}
 
 	if len(vals) != 1 {
-		return propertyError(col.Name, "default", "太多的值")
+		return errors.New("太多的值")
 	}
 
 	col.HasDefault = true
@@ -291,11 +362,11 @@ func (m *Model) setDefault(col *Column, vals []string) error {
 // index(idx_name)
 func (m *Model) setIndex(col *Column, vals []string) error {
 	if len(vals) != 1 {
-		return propertyError(col.Name, "index", "太多的值")
+		return errors.New("太多的值")
 	}
 
 	if typ := m.hasConstraint(vals[0], index); typ != none {
-		return propertyError(col.Name, "index", "已经存在相同的约束名")
+		return errors.New("已经存在相同的约束名")
 	}
 
 	name := strings.ToLower(vals[0])
@@ -307,11 +378,11 @@ func (m *Model) setIndex(col *Column, vals []string) error {
 // pk
 func (m *Model) setPK(col *Column, vals []string) error {
 	if len(vals) != 0 {
-		return propertyError(col.Name, "pk", "太多的值")
+		return errors.New("太多的值")
 	}
 
 	if m.AI != nil {
-		return propertyError(col.Name, "pk", "已经存在自增列，不需要再次指定主键")
+		return errors.New("已经存在自增列，不需要再次指定主键")
 	}
 
 	m.PK = append(m.PK, col)
@@ -321,11 +392,11 @@ func (m *Model) setPK(col *Column, vals []string) error {
 // unique(unique_name)
 func (m *Model) setUnique(col *Column, vals []string) error {
 	if len(vals) != 1 {
-		return propertyError(col.Name, "unique", "只能带一个参数")
+		return errors.New("只能带一个参数")
 	}
 
 	if typ := m.hasConstraint(vals[0], unique); typ != none {
-		return propertyError(col.Name, "unique", "已经存在相同的约束名")
+		return errors.New("已经存在相同的约束名")
 	}
 
 	name := strings.ToLower(vals[0])
@@ -338,15 +409,15 @@ func (m *Model) setUnique(col *Column, vals []string) error {
 // fk(fk_name,refTable,refColName,updateRule,deleteRule)
 func (m *Model) setFK(col *Column, vals []string) error {
 	if len(vals) < 3 {
-		return propertyError(col.Name, "fk", "参数不够")
+		return errors.New("参数不够")
 	}
 
 	if typ := m.hasConstraint(vals[0], fk); typ != none {
-		return propertyError(col.Name, "fk", "已经存在相同的约束名")
+		return errors.New("已经存在相同的约束名")
 	}
 
 	if _, found := m.FK[vals[0]]; found {
-		return propertyError(col.Name, "fk", "重复的外键约束名")
+		return errors.New("重复的外键约束名")
 	}
 
 	fkInst := &ForeignKey{
@@ -371,22 +442,22 @@ func (m *Model) setFK(col *Column, vals []string) error {
 // ai
 func (m *Model) setAI(col *Column, vals []string) (err error) {
 	if col.HasDefault {
-		return propertyError(col.Name, "ai", "不能将一个含有默认值的列设置为自增")
+		return errors.New("不能将一个含有默认值的列设置为自增")
 	}
 
 	if len(vals) != 0 {
-		return propertyError(col.Name, "ai", "太多的值")
+		return errors.New("太多的值")
 	}
 
 	if col.Nullable {
-		return propertyError(col.Name, "ai", "不能与 nullable 并存")
+		return errors.New("不能与 nullable 并存")
 	}
 
 	switch col.GoType.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 	default:
-		return propertyError(col.Name, "ai", "类型只能是数值")
+		return errors.New("类型只能是数值")
 	}
 
 	m.AI = col
@@ -409,6 +480,8 @@ func (m *Model) hasConstraint(name string, except conType) conType {
 }
 
 // ClearModels 清除所有的 Model 缓存。
+//
+// 通过 ModelBuilder 注册的模型不会被清除。
 func ClearModels() {
 	models.Lock()
 	defer models.Unlock()
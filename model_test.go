@@ -129,6 +129,26 @@ func TestModel_parseColumn(t *testing.T) {
 
 	// 不存在的属性名称
 	a.Error(m.parseColumn(col, "not-exists-property(p1)"))
+
+	// 错误信息中包含字段名和位置
+	m.Name = "User"
+	col.GoName = "Name"
+	err := m.parseColumn(col, "len(20);name(m1,m2)")
+	terr, ok := err.(*TagError)
+	a.True(ok).
+		Equal(terr.Field, "User.Name").
+		Equal(terr.Offset, 8).
+		Contains(terr.Error(), "name 属性")
+
+	// 语法错误
+	err = m.parseColumn(col, "len(20);default('abc)")
+	terr, ok = err.(*TagError)
+	a.True(ok).Equal(terr.Offset, 16)
+
+	// 包含逗号的默认值
+	col = &Column{GoType: reflect.TypeOf("")}
+	a.NotError(m.parseColumn(col, "name(title);len(20);default('a,b')"))
+	a.True(col.HasDefault).Equal(col.Default, "a,b")
 }
 
 func TestModel_parseMeta(t *testing.T) {
@@ -156,6 +176,16 @@ func TestModel_parseMeta(t *testing.T) {
 	// check 与其它约束名相同
 	m.constraints = map[string]conType{"fk": fk}
 	a.Error(m.parseMeta("check(fk,id>0)"))
+
+	// 表达式中包含逗号和括号
+	a.NotError(m.parseMeta("check(chk_in, type IN (1,2));name(m1)"))
+	a.Equal(m.Check["chk_in"], "type IN (1,2)")
+
+	err := m.parseMeta("name(m1);check(chk, id>0")
+	terr, ok := err.(*TagError)
+	a.True(ok).
+		Equal(terr.Field, "m1.Meta()").
+		Equal(terr.Offset, 14)
 }
 
 func TestModel_setOCC(t *testing.T) {
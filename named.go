@@ -0,0 +1,143 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+// 将 query 中的命名参数 @name 和 :name 转换成 ? 占位符，
+// 并按占位符出现的顺序重新排列 args 中的值。
+//
+// 同一个命名参数可以在语句中出现多次，其值会被重复添加到返回的参数列表中；
+// 普通的 ? 占位符则依次对应 args 中非命名参数的值。
+// 引号和 -- 及 /* */ 注释中的内容不作处理，:: 和 @@ 也会被忽略，
+// 以避免与 postgresql 的类型转换和 mysql 的系统变量冲突。
+// backslash 表示引号中的 \ 是否为转义字符，具体可参考 Dialect.BackslashEscapes()。
+//
+// 语句中的名称在 args 中不存在，或是 args 中的命名参数未被使用，均返回错误。
+// 若 args 中不包含 sql.NamedArg，则原样返回。
+func convertNamedArgs(query string, args []interface{}, backslash bool) (string, []interface{}, error) {
+	var named map[string]interface{}
+	positional := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if n, ok := arg.(sql.NamedArg); ok && n.Name != "" {
+			if named == nil {
+				named = make(map[string]interface{}, len(args))
+			}
+			named[n.Name] = n.Value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	if len(named) == 0 {
+		return query, args, nil
+	}
+
+	used := make(map[string]bool, len(named))
+	buf := make([]byte, 0, len(query))
+	ret := make([]interface{}, 0, len(args))
+	var quote byte // 当前所在的引号，为 0 表示不在引号中
+	pos := 0       // 下一个普通参数在 positional 中的位置
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		if quote != 0 {
+			buf = append(buf, c)
+			switch {
+			case backslash && c == '\\' && quote != '`' && i+1 < len(query): // 以 \ 转义的引号
+				i++
+				buf = append(buf, query[i])
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+			buf = append(buf, c)
+		case '-', '/':
+			end := commentEnd(query, i)
+			buf = append(buf, query[i:end]...)
+			i = end - 1
+		case '?':
+			if pos >= len(positional) {
+				return "", nil, sqlbuilder.ErrArgsNotMatch
+			}
+			ret = append(ret, positional[pos])
+			pos++
+			buf = append(buf, '?')
+		case '@', ':':
+			if (i > 0 && query[i-1] == c) ||
+				i+1 >= len(query) || query[i+1] == c || !isNameStart(query[i+1]) {
+				buf = append(buf, c)
+				continue
+			}
+
+			end := i + 1
+			for end < len(query) && isNameChar(query[end]) {
+				end++
+			}
+
+			name := query[i+1 : end]
+			val, found := named[name]
+			if !found {
+				return "", nil, fmt.Errorf("未找到命名参数 %s 对应的值", name)
+			}
+			used[name] = true
+			ret = append(ret, val)
+			buf = append(buf, '?')
+			i = end - 1
+		default:
+			buf = append(buf, c)
+		}
+	}
+
+	if pos != len(positional) {
+		return "", nil, sqlbuilder.ErrArgsNotMatch
+	}
+
+	for name := range named {
+		if !used[name] {
+			return "", nil, fmt.Errorf("命名参数 %s 未被使用", name)
+		}
+	}
+
+	return string(buf), ret, nil
+}
+
+// 若 query[i:] 以 -- 或 /* 开头，返回该注释结束的位置，否则返回 i+1。
+func commentEnd(query string, i int) int {
+	switch {
+	case strings.HasPrefix(query[i:], "--"):
+		if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	default:
+		return i + 1
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+func TestConvertNamedArgs(t *testing.T) {
+	a := assert.New(t)
+
+	// 不包含命名参数，原样返回
+	query, args, err := convertNamedArgs("SELECT * FROM t WHERE id=@id", []interface{}{1}, true)
+	a.NotError(err).
+		Equal(query, "SELECT * FROM t WHERE id=@id").
+		Equal(args, []interface{}{1})
+
+	// @name 和 :name
+	query, args, err = convertNamedArgs("SELECT * FROM t WHERE id=@id AND name=:name", []interface{}{
+		sql.Named("name", "n1"),
+		sql.Named("id", 5),
+	}, true)
+	a.NotError(err).
+		Equal(query, "SELECT * FROM t WHERE id=? AND name=?").
+		Equal(args, []interface{}{5, "n1"})
+
+	// 重复的命名参数，与普通参数混用
+	query, args, err = convertNamedArgs("SELECT * FROM t WHERE (id=@id OR pid=@id) AND age>? LIMIT @limit", []interface{}{
+		sql.Named("id", 5),
+		18,
+		sql.Named("limit", 10),
+	}, true)
+	a.NotError(err).
+		Equal(query, "SELECT * FROM t WHERE (id=? OR pid=?) AND age>? LIMIT ?").
+		Equal(args, []interface{}{5, 5, 18, 10})
+
+	// 引号、:: 及 @@ 中的内容不作转换
+	query, args, err = convertNamedArgs("SELECT '@id', \"a:b\", @@version, id::text FROM t WHERE id=:id", []interface{}{
+		sql.Named("id", 5),
+	}, true)
+	a.NotError(err).
+		Equal(query, "SELECT '@id', \"a:b\", @@version, id::text FROM t WHERE id=?").
+		Equal(args, []interface{}{5})
+
+	// 不存在的命名参数
+	query, args, err = convertNamedArgs("SELECT * FROM t WHERE id=@id AND name=@nmae", []interface{}{
+		sql.Named("id", 5),
+		sql.Named("name", "n1"),
+	}, true)
+	a.Error(err).Empty(query).Nil(args)
+
+	// 未被使用的命名参数
+	query, args, err = convertNamedArgs("SELECT * FROM t WHERE id=@id", []interface{}{
+		sql.Named("id", 5),
+		sql.Named("name", "n1"),
+	}, true)
+	a.Error(err).Empty(query).Nil(args)
+
+	// 注释中的内容不作转换
+	query, args, err = convertNamedArgs("SELECT * FROM t -- id=:id?\nWHERE /* :name? */ id=:id AND age>5-1", []interface{}{
+		sql.Named("id", 5),
+	}, true)
+	a.NotError(err).
+		Equal(query, "SELECT * FROM t -- id=:id?\nWHERE /* :name? */ id=? AND age>5-1").
+		Equal(args, []interface{}{5})
+
+	// 以 \ 转义的引号，仅在 backslash 为 true 时有效
+	query, args, err = convertNamedArgs(`SELECT * FROM t WHERE title='it\'s :x?' AND id=:id`, []interface{}{
+		sql.Named("id", 5),
+	}, true)
+	a.NotError(err).
+		Equal(query, `SELECT * FROM t WHERE title='it\'s :x?' AND id=?`).
+		Equal(args, []interface{}{5})
+
+	query, args, err = convertNamedArgs(`SELECT * FROM t WHERE path='C:\' AND id=:id`, []interface{}{
+		sql.Named("id", 5),
+	}, false)
+	a.NotError(err).
+		Equal(query, `SELECT * FROM t WHERE path='C:\' AND id=?`).
+		Equal(args, []interface{}{5})
+
+	// 普通参数数量不匹配
+	_, _, err = convertNamedArgs("SELECT * FROM t WHERE id=@id AND age>?", []interface{}{
+		sql.Named("id", 5),
+	}, true)
+	a.Equal(err, sqlbuilder.ErrArgsNotMatch)
+
+	_, _, err = convertNamedArgs("SELECT * FROM t WHERE id=@id", []interface{}{
+		sql.Named("id", 5),
+		18,
+	}, true)
+	a.Equal(err, sqlbuilder.ErrArgsNotMatch)
+}
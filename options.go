@@ -0,0 +1,141 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Options 初始化 DB 实例的参数，用于 NewDBWithOptions()。
+type Options struct {
+	DriverName     string
+	DataSourceName string
+	TablePrefix    string
+	Dialect        Dialect
+
+	// 连接池的设置，具体可参考 sql.DB 的同名方法。
+	//
+	// MaxIdleConns 为 0 表示采用 database/sql 的默认值，小于 0 表示不保留空闲连接；
+	// MaxOpenConns 和 ConnMaxLifetime 为 0 表示不限制。
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// 初始化时 Ping 数据库的超时时间，为 0 表示不 Ping。
+	// 同时也作为健康检测中每次 Ping 的超时时间。
+	PingTimeout time.Duration
+
+	// 健康检测的时间间隔，为 0 表示不检测。
+	HealthCheckInterval time.Duration
+
+	// 每次健康检测之后调用，stats 为当前连接池的状态，err 为 Ping 返回的错误。
+	//
+	// 可以在此处收集连接池的状态或是在出错时报警，
+	// 指定了 HealthCheckInterval 时不能为空。
+	HealthCheck func(stats sql.DBStats, err error)
+
+	// 统计数据的收集，为空表示不收集，具体可参考 DB.WithCollector()。
+	//
+	// 若同时指定了 HealthCheckInterval，每次健康检测时也会更新连接池的状态。
+	Collector Collector
+
+	// 追踪，为空表示不追踪，具体可参考 DB.WithTracer()。
+	Tracer Tracer
+}
+
+// 定时执行的健康检测，在 DB.Close() 时停止。
+type healthChecker struct {
+	done chan struct{}
+	once sync.Once
+}
+
+// NewDBWithOptions 根据 opt 声明一个新的 DB 实例。
+//
+// 与 NewDB() 不同，可以同时设置连接池的参数，并在初始化时检测数据库是否可用。
+func NewDBWithOptions(opt *Options) (*DB, error) {
+	if opt.HealthCheckInterval > 0 && opt.HealthCheck == nil {
+		return nil, errors.New("指定了 HealthCheckInterval 但 HealthCheck 为空")
+	}
+
+	stdDB, err := sql.Open(opt.DriverName, opt.DataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.MaxOpenConns > 0 {
+		stdDB.SetMaxOpenConns(opt.MaxOpenConns)
+	}
+	if opt.MaxIdleConns != 0 {
+		stdDB.SetMaxIdleConns(opt.MaxIdleConns)
+	}
+	if opt.ConnMaxLifetime > 0 {
+		stdDB.SetConnMaxLifetime(opt.ConnMaxLifetime)
+	}
+
+	if opt.PingTimeout > 0 {
+		if err = ping(stdDB, opt.PingTimeout); err != nil {
+			stdDB.Close()
+			return nil, err
+		}
+	}
+
+	db, err := NewDBWithStdDB(stdDB, opt.TablePrefix, opt.Dialect)
+	if err != nil {
+		stdDB.Close()
+		return nil, err
+	}
+
+	db.collector = opt.Collector
+	db.tracer = opt.Tracer
+
+	if opt.HealthCheckInterval > 0 {
+		db.health = &healthChecker{done: make(chan struct{})}
+		go db.health.run(db, opt)
+	}
+
+	return db, nil
+}
+
+func ping(db *sql.DB, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return db.PingContext(ctx)
+}
+
+func (h *healthChecker) run(db *DB, opt *Options) {
+	ticker := time.NewTicker(opt.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+			err := ping(db.stdDB, opt.PingTimeout)
+			opt.HealthCheck(db.Stats(), err)
+			db.CollectStats()
+		}
+	}
+}
+
+func (h *healthChecker) stop() {
+	h.once.Do(func() {
+		close(h.done)
+	})
+}
+
+// Stats 返回主数据库连接池的状态
+func (db *DB) Stats() sql.DBStats {
+	return db.stdDB.Stats()
+}
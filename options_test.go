@@ -0,0 +1,68 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+)
+
+func TestNewDBWithOptions(t *testing.T) {
+	a := assert.New(t)
+	newDB(a).Close() // 初始化 dsn 和 d
+
+	checked := make(chan error, 10)
+	db, err := orm.NewDBWithOptions(&orm.Options{
+		DriverName:          driver,
+		DataSourceName:      dsn,
+		TablePrefix:         prefix,
+		Dialect:             d,
+		MaxOpenConns:        5,
+		MaxIdleConns:        -1,
+		ConnMaxLifetime:     time.Minute,
+		PingTimeout:         time.Second,
+		HealthCheckInterval: 10 * time.Millisecond,
+		HealthCheck: func(stats sql.DBStats, err error) {
+			select {
+			case checked <- err:
+			default:
+			}
+		},
+	})
+	a.NotError(err).NotNil(db)
+	defer closeDB(a)
+
+	a.Equal(db.Stats().MaxOpenConnections, 5)
+
+	select {
+	case err := <-checked:
+		a.NotError(err)
+	case <-time.After(time.Second):
+		t.Error("健康检测未执行")
+	}
+
+	a.NotError(db.Close())
+	a.NotError(db.Close()) // 多次关闭不会 panic
+
+	// 未指定 HealthCheck
+	db, err = orm.NewDBWithOptions(&orm.Options{
+		DriverName:          driver,
+		DataSourceName:      dsn,
+		Dialect:             d,
+		HealthCheckInterval: time.Second,
+	})
+	a.Error(err).Nil(db)
+
+	// 无效的驱动
+	db, err = orm.NewDBWithOptions(&orm.Options{
+		DriverName: "not-exists",
+		Dialect:    d,
+	})
+	a.Error(err).Nil(db)
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ormtest
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// 以下为 database/sql/driver 的实现，所有的语句都会被记录到 Recorder 中。
+
+type connector struct {
+	rec *Recorder
+}
+
+type conn struct {
+	rec  *Recorder
+	inTx bool
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+type rows struct {
+	cols  []string
+	rows  [][]interface{}
+	index int
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+var (
+	_ driver.Connector          = &connector{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+	_ driver.StmtExecContext    = &stmt{}
+	_ driver.StmtQueryContext   = &stmt{}
+	_ driver.Rows               = &rows{}
+	_ driver.Result             = result{}
+	_ driver.Tx                 = &conn{}
+	_ driver.SessionResetter    = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+)
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{rec: c.rec}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c
+}
+
+// Open 实现 driver.Driver 接口，仅为了满足 Connector.Driver() 的要求。
+func (c *connector) Open(string) (driver.Conn, error) {
+	return &conn{rec: c.rec}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.inTx {
+		return nil, errors.New("已经在事务中")
+	}
+	c.inTx = true
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	c.inTx = false
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	c.inTx = false
+	return nil
+}
+
+func (c *conn) ResetSession(context.Context) error {
+	return nil
+}
+
+// CheckNamedValue 接受所有类型的参数，以便记录参数的原始值。
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ret := c.rec.record(query, args, c.inTx)
+	if ret.Err != nil {
+		return nil, ret.Err
+	}
+	return result{lastInsertID: ret.LastInsertID, rowsAffected: ret.RowsAffected}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	ret := c.rec.record(query, args, c.inTx)
+	if ret.Err != nil {
+		return nil, ret.Err
+	}
+	return &rows{cols: ret.Columns, rows: ret.Rows}, nil
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	vals := make([]driver.NamedValue, 0, len(args))
+	for i, arg := range args {
+		vals = append(vals, driver.NamedValue{Ordinal: i + 1, Value: arg})
+	}
+	return vals
+}
+
+func (r *rows) Columns() []string {
+	return r.cols
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.index >= len(r.rows) {
+		return io.EOF
+	}
+
+	row := r.rows[r.index]
+	r.index++
+	for i := range dest {
+		if i < len(row) {
+			dest[i] = row[i]
+		}
+	}
+	return nil
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package ormtest 提供了在没有数据库的情况下测试 orm 相关代码的工具。
+//
+// New() 返回的 orm.DB 实例并不会真正执行 SQL 语句，
+// 而是将生成的语句及参数记录在 Recorder 中，并返回预设的结果，
+// 可用于单元测试中断言生成的语句，或是在执行之前预览 DDL：
+//  db, rec := ormtest.New(dialect.Mysql(), "prefix_")
+//  db.Create(&User{})
+//  for _, stmt := range rec.Statements() {
+//      fmt.Println(stmt.Query)
+//  }
+package ormtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/sqlnorm"
+)
+
+// Statement 表示一条被记录的语句
+type Statement struct {
+	// 最终发送给数据库的语句，已经替换了表名前缀，并经过 Dialect.SQL() 转换。
+	Query string
+
+	// 语句的参数，命名参数已经被转换成普通的参数。
+	Args []interface{}
+
+	// 是否在事务中执行
+	Tx bool
+}
+
+// Result 预设的执行结果
+//
+// 对于 Exec 执行的语句，使用 LastInsertID 和 RowsAffected；
+// 对于查询语句，则使用 Columns 和 Rows。
+type Result struct {
+	LastInsertID int64
+	RowsAffected int64
+
+	Columns []string
+	Rows    [][]interface{}
+
+	// 不为空时，执行语句直接返回该错误，错误会经过 Dialect.TranslateError() 转换。
+	Err error
+}
+
+// Recorder 记录由 New() 返回的 DB 实例执行的语句
+type Recorder struct {
+	mu      sync.Mutex
+	stmts   []*Statement
+	results []*Result
+}
+
+// New 声明一个不会真正执行语句的 orm.DB 实例
+//
+// 未通过 Recorder.Push() 预设结果时，
+// Exec 执行的语句返回 LastInsertID 和 RowsAffected 均为 1 的结果，
+// 查询语句则返回空的结果集。
+func New(d orm.Dialect, tablePrefix string) (*orm.DB, *Recorder) {
+	rec := &Recorder{}
+
+	db, err := orm.NewDBWithStdDB(sql.OpenDB(&connector{rec: rec}), tablePrefix, d)
+	if err != nil { // NewDBWithStdDB 不会返回错误
+		panic(err)
+	}
+
+	return db, rec
+}
+
+// Push 预设执行结果
+//
+// 每执行一条语句，会按顺序使用一个预设结果，用完之后则使用默认的结果。
+func (rec *Recorder) Push(results ...*Result) {
+	rec.mu.Lock()
+	rec.results = append(rec.results, results...)
+	rec.mu.Unlock()
+}
+
+// Statements 返回所有被记录的语句
+func (rec *Recorder) Statements() []*Statement {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return append([]*Statement{}, rec.stmts...)
+}
+
+// Queries 返回所有被记录的语句内容
+func (rec *Recorder) Queries() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	queries := make([]string, 0, len(rec.stmts))
+	for _, stmt := range rec.stmts {
+		queries = append(queries, stmt.Query)
+	}
+	return queries
+}
+
+// Reset 清空记录的语句以及未使用的预设结果
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	rec.stmts = rec.stmts[:0]
+	rec.results = rec.results[:0]
+	rec.mu.Unlock()
+}
+
+// 记录语句，并返回对应的执行结果。
+func (rec *Recorder) record(query string, args []driver.NamedValue, tx bool) *Result {
+	vals := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		vals = append(vals, arg.Value)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.stmts = append(rec.stmts, &Statement{Query: query, Args: vals, Tx: tx})
+
+	if len(rec.results) == 0 {
+		return &Result{LastInsertID: 1, RowsAffected: 1}
+	}
+
+	ret := rec.results[0]
+	rec.results = rec.results[1:]
+	return ret
+}
+
+// Equal 检测两条 SQL 语句是否相等，忽略大小写与多余的空格。
+func Equal(s1, s2 string) bool {
+	return sqlnorm.Normalize(s1) == sqlnorm.Normalize(s2)
+}
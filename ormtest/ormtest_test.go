@@ -0,0 +1,87 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package ormtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+func TestNew(t *testing.T) {
+	a := assert.New(t)
+
+	db, rec := New(dialect.Sqlite3(), "p_")
+	a.NotNil(db).NotNil(rec)
+	defer func() {
+		a.NotError(db.Close())
+	}()
+
+	// DDL
+	a.NotError(db.Create(&modeltest.Group{}))
+	stmts := rec.Statements()
+	a.Equal(len(stmts), 1).True(stmts[0].Tx) // sqlite3 的 DDL 在事务中执行
+	a.Contains(stmts[0].Query, "CREATE TABLE IF NOT EXISTS `p_groups`")
+
+	// insert
+	rec.Reset()
+	_, err := db.Insert(&modeltest.Group{ID: 1, Name: "g1"})
+	a.NotError(err)
+	stmts = rec.Statements()
+	a.Equal(len(stmts), 1)
+	a.Contains(stmts[0].Query, "INSERT INTO `p_groups`")
+	a.Equal(len(stmts[0].Args), 3)
+
+	// update，默认结果中 RowsAffected 为 1
+	rec.Reset()
+	_, err = db.Update(&modeltest.Group{ID: 1, Name: "g2"})
+	a.NotError(err)
+	_, err = db.Update(&modeltest.Group{ID: 1}, "name")
+	a.NotError(err)
+	a.True(Equal(rec.Queries()[1], "UPDATE `p_groups` SET `id`=?,`name`=? WHERE `id`=?"))
+	a.Equal(rec.Statements()[1].Args, []interface{}{int64(1), "", int64(1)})
+
+	// 预设的结果
+	rec.Reset()
+	rec.Push(&Result{RowsAffected: 0})
+	_, err = db.CheckAffected().Delete(&modeltest.Group{ID: 1})
+	a.Equal(err, orm.ErrNoRows)
+
+	rec.Push(&Result{Columns: []string{"id", "name"}, Rows: [][]interface{}{{int64(1), "g1"}}})
+	g := &modeltest.Group{ID: 1}
+	a.NotError(db.Select(g))
+	a.Equal(g.Name, "g1")
+
+	// 未预设结果的查询返回空结果集
+	a.Equal(db.Select(&modeltest.Group{ID: 2}), orm.ErrNoRows)
+
+	// 错误会经过 Dialect.TranslateError() 转换
+	rec.Push(&Result{Err: errors.New("UNIQUE constraint failed: p_groups.name")})
+	_, err = db.Insert(&modeltest.Group{ID: 2, Name: "g1"})
+	a.True(errors.Is(err, orm.ErrUniqueViolation))
+
+	// 事务
+	rec.Reset()
+	tx, err := db.Begin()
+	a.NotError(err)
+	_, err = tx.Insert(&modeltest.Group{ID: 3, Name: "g3"})
+	a.NotError(err)
+	a.NotError(tx.Commit())
+	_, err = db.Insert(&modeltest.Group{ID: 4, Name: "g4"})
+	a.NotError(err)
+	stmts = rec.Statements()
+	a.Equal(len(stmts), 2).True(stmts[0].Tx).False(stmts[1].Tx)
+}
+
+func TestEqual(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(Equal("insert   INTO tb2 (c1, c2) values (?, ?) , (? ,@c2)", "insert into tb2 (c1,c2) values (?,?),(?,@c2)"))
+	a.False(Equal("insert into tb1 (c1) values (?)", "insert into tb2 (c1) values (?)"))
+}
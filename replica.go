@@ -0,0 +1,129 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Replica 表示一个只读的从数据库
+type Replica struct {
+	DB *sql.DB
+
+	// 权重，值越大被选中的机会越多，小于等于 0 时按 1 处理。
+	Weight int
+}
+
+// 从数据库列表，采用平滑的加权轮询算法选择数据库。
+type replicas struct {
+	sync.Mutex
+	items   []*Replica
+	weights []int // 各个数据库的有效权重
+	current []int // 各个数据库的当前权重
+	total   int
+}
+
+func newReplicas(items []*Replica) (*replicas, error) {
+	r := &replicas{
+		items:   items,
+		weights: make([]int, len(items)),
+		current: make([]int, len(items)),
+	}
+
+	for i, item := range items {
+		if item == nil || item.DB == nil {
+			return nil, errors.New("无效的从数据库")
+		}
+
+		w := item.Weight
+		if w <= 0 {
+			w = 1
+		}
+		r.weights[i] = w
+		r.total += w
+	}
+
+	return r, nil
+}
+
+// 选择下一个用于读取的数据库
+func (r *replicas) next() *sql.DB {
+	if len(r.items) == 1 {
+		return r.items[0].DB
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	index := 0
+	for i, w := range r.weights {
+		r.current[i] += w
+		if r.current[i] > r.current[index] {
+			index = i
+		}
+	}
+	r.current[index] -= r.total
+
+	return r.items[index].DB
+}
+
+func (r *replicas) close() error {
+	var err error
+	for _, item := range r.items {
+		if e := item.DB.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// NewDBWithReplicas 从一个主数据库和多个从数据库构建 DB 实例。
+//
+// Query、QueryRow、Select 和 Count 等只读操作会按 Replica.Weight
+// 以加权轮询的方式分配到各个从数据库，其它操作以及事务中的所有操作均在主数据库中执行。
+// 若需要读取刚写入的数据，可以通过 DB.Primary() 从主数据库读取。
+//
+// 若 replicas 为空，则与 NewDBWithStdDB 相同。
+func NewDBWithReplicas(primary *sql.DB, replicas []*Replica, tablePrefix string, dialect Dialect) (*DB, error) {
+	db, err := NewDBWithStdDB(primary, tablePrefix, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicas) == 0 {
+		return db, nil
+	}
+
+	if db.replicas, err = newReplicas(replicas); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Primary 返回一个所有操作都在主数据库中执行的 DB 实例。
+//
+// 返回的实例与当前实例共用连接，用于在写入之后需要立即读取数据的场景。
+// 若当前实例未指定从数据库，则返回其本身。
+func (db *DB) Primary() *DB {
+	if db.replicas == nil || db.primary {
+		return db
+	}
+
+	inst := *db
+	inst.primary = true
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// 返回用于执行只读操作的数据库
+func (db *DB) reader() *sql.DB {
+	if db.replicas == nil || db.primary {
+		return db.stdDB
+	}
+	return db.replicas.next()
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+)
+
+// 创建一个 sqlite3 数据库，其中 #source 表仅有一条值为 name 的记录。
+func newSourceDB(a *assert.Assertion, file, name string) *sql.DB {
+	db, err := sql.Open("sqlite3", file)
+	a.NotError(err).NotNil(db)
+
+	_, err = db.Exec("CREATE TABLE prefix_source(name TEXT)")
+	a.NotError(err)
+	_, err = db.Exec("INSERT INTO prefix_source(name) VALUES(?)", name)
+	a.NotError(err)
+
+	return db
+}
+
+func querySource(a *assert.Assertion, e orm.Engine) string {
+	var name string
+	a.NotError(e.QueryRow("SELECT {name} FROM #source").Scan(&name))
+	return name
+}
+
+// 模拟 postgres 的 RETURNING 子句，使 InsertStmt.LastInsertID() 通过 QueryRow() 执行插入语句。
+// sqlite3 不支持 RETURNING，所以只追加一条注释。
+type returningDialect struct {
+	orm.Dialect
+}
+
+func (d *returningDialect) LastInsertID(table, col string) (string, bool) {
+	return " -- RETURNING {" + col + "}", true
+}
+
+func TestNewDBWithReplicas(t *testing.T) {
+	a := assert.New(t)
+	files := []string{"./orm_primary.db", "./orm_replica1.db", "./orm_replica2.db"}
+	defer func() {
+		for _, file := range files {
+			a.NotError(os.Remove(file))
+		}
+	}()
+
+	primary := newSourceDB(a, files[0], "primary")
+	r1 := newSourceDB(a, files[1], "r1")
+	r2 := newSourceDB(a, files[2], "r2")
+
+	db, err := orm.NewDBWithReplicas(primary, []*orm.Replica{
+		{DB: r1, Weight: 2},
+		{DB: r2},
+	}, "prefix_", dialect.Sqlite3())
+	a.NotError(err).NotNil(db)
+	defer func() {
+		a.NotError(db.Close())
+		a.Error(r1.Ping()) // 从数据库也被关闭
+	}()
+
+	// 按权重轮询
+	a.Equal(querySource(a, db), "r1")
+	a.Equal(querySource(a, db), "r2")
+	a.Equal(querySource(a, db), "r1")
+	a.Equal(querySource(a, db), "r1")
+
+	// 从主数据库读取
+	p := db.Primary()
+	a.Equal(querySource(a, p), "primary")
+	a.True(p.Primary() == p)
+
+	// 写入在主数据库
+	_, err = db.Exec("UPDATE #source SET {name}=?", "p2")
+	a.NotError(err)
+	a.Equal(querySource(a, db.Primary()), "p2")
+
+	// InsertStmt.LastInsertID() 的 RETURNING 在主数据库
+	db3, err := orm.NewDBWithReplicas(primary, []*orm.Replica{{DB: r1}}, "prefix_", &returningDialect{Dialect: dialect.Sqlite3()})
+	a.NotError(err).NotNil(db3)
+	_, err = db3.SQL().Insert().Table("#source").Columns("{name}").Values("p3").LastInsertID("#source", "name")
+	a.Equal(err, sql.ErrNoRows) // 未返回任何数据，但插入语句已执行
+	var count int
+	a.NotError(primary.QueryRow("SELECT COUNT(*) FROM prefix_source").Scan(&count))
+	a.Equal(count, 2)
+	a.NotError(r1.QueryRow("SELECT COUNT(*) FROM prefix_source").Scan(&count))
+	a.Equal(count, 1)
+
+	// 事务在主数据库
+	tx, err := db.Begin()
+	a.NotError(err)
+	a.Equal(querySource(a, tx), "p2")
+	a.NotError(tx.Commit())
+
+	// 无效的从数据库
+	db2, err := orm.NewDBWithReplicas(primary, []*orm.Replica{{}}, "prefix_", dialect.Sqlite3())
+	a.Error(err).Nil(db2)
+
+	// 不指定从数据库
+	db2, err = orm.NewDBWithReplicas(primary, nil, "prefix_", dialect.Sqlite3())
+	a.NotError(err).NotNil(db2)
+	a.True(db2.Primary() == db2)
+}
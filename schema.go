@@ -0,0 +1,299 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 按外键依赖关系排序之后的表模型
+type schema struct {
+	models []*Model // 被引用的表在前
+
+	// 需要在所有表创建之后再通过 ALTER TABLE 添加的外键
+	alters []cycleFK
+}
+
+// WriteSchema 将 models 的表结构以 SQL 脚本的形式写入 w
+//
+// 脚本中包含了创建表、约束以及索引的语句，表按外键的依赖关系排序，
+// 被引用的表在前；外键存在循环引用时，部分外键会在所有表创建之后，
+// 通过 ALTER TABLE 语句添加。语句中的 # 和 {} 已经根据 tablePrefix 和 d 作了替换，
+// 每条语句以分号结尾。适用于需要人工审核 DDL 的场景，可以代替 DB.Create()。
+func WriteSchema(w io.Writer, d Dialect, tablePrefix string, models ...interface{}) error {
+	s, err := newSchema(d, models)
+	if err != nil {
+		return err
+	}
+
+	replacer := newReplacer(tablePrefix, d)
+	write := func(query string) error {
+		query, err := d.SQL(replacer.Replace(query))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, query+";\n")
+		return err
+	}
+
+	for i, m := range s.models {
+		sqls, err := d.CreateTableSQL(s.table(m))
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			if _, err = io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err = fmt.Fprintf(w, "-- %s%s\n", tablePrefix, m.Name); err != nil {
+			return err
+		}
+
+		for _, query := range sqls {
+			if err = write(query); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.alters) == 0 {
+		return nil
+	}
+
+	if _, err = io.WriteString(w, "\n-- foreign keys\n"); err != nil {
+		return err
+	}
+	for _, fk := range s.alters {
+		query, err := d.AddForeignKeySQL(fk.model, fk.name)
+		if err != nil {
+			return err
+		}
+
+		if err = write(query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 按外键依赖关系创建 objs 对应的表。
+func multCreate(e Engine, objs []interface{}) error {
+	s, err := newSchema(e.Dialect(), objs)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range s.models {
+		if err := createModel(e, s.table(m)); err != nil {
+			return err
+		}
+	}
+
+	for _, fk := range s.alters {
+		query, err := e.Dialect().AddForeignKeySQL(fk.model, fk.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err = e.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 按外键依赖关系的反序删除 objs 对应的表。
+//
+// 存在循环引用的外键会在删除表之前先被删除。
+func multDrop(e Engine, objs []interface{}) error {
+	s, err := newSchema(e.Dialect(), objs)
+	if err != nil {
+		return err
+	}
+
+	for i := len(s.alters) - 1; i >= 0; i-- {
+		fk := s.alters[i]
+		query, err := e.Dialect().DropForeignKeySQL(fk.model, fk.name)
+		if err != nil {
+			return err
+		}
+
+		if query == "" {
+			continue
+		}
+
+		if _, err = e.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	for i := len(s.models) - 1; i >= 0; i-- {
+		if err := dropModel(e, s.models[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 按外键依赖关系的反序返回 objs 对应的表模型，引用其它表的表在前。
+func reverseModels(objs []interface{}) ([]*Model, error) {
+	ms, err := newModels(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, _ = sortModels(ms)
+	for i, j := 0, len(ms)-1; i < j; i, j = i+1, j-1 {
+		ms[i], ms[j] = ms[j], ms[i]
+	}
+	return ms, nil
+}
+
+func newModels(objs []interface{}) ([]*Model, error) {
+	ms := make([]*Model, 0, len(objs))
+	for _, v := range objs {
+		m, err := NewModel(v)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+
+	return ms, nil
+}
+
+func newSchema(d Dialect, objs []interface{}) (*schema, error) {
+	ms, err := newModels(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, cycles := sortModels(ms)
+	s := &schema{
+		models: ms,
+		alters: make([]cycleFK, 0, len(cycles)),
+	}
+
+	for _, c := range cycles {
+		// 返回空字符串，表示数据库允许外键引用尚未创建的表，
+		// 外键直接包含在创建表的语句中即可。
+		query, err := d.AddForeignKeySQL(c.model, c.name)
+		if err != nil {
+			return nil, err
+		}
+		if query == "" {
+			continue
+		}
+
+		s.alters = append(s.alters, c)
+	}
+
+	return s, nil
+}
+
+// 返回用于生成创建表语句的模型，不包含需要延后添加的外键。
+func (s *schema) table(m *Model) *Model {
+	var mm *Model
+	for _, fk := range s.alters {
+		if fk.model != m {
+			continue
+		}
+
+		if mm == nil {
+			cp := *m
+			cp.FK = make(map[string]*ForeignKey, len(m.FK))
+			for name, v := range m.FK {
+				cp.FK[name] = v
+			}
+			mm = &cp
+		}
+		delete(mm.FK, fk.name)
+	}
+
+	if mm == nil {
+		return m
+	}
+	return mm
+}
+
+// 形成循环引用的外键
+type cycleFK struct {
+	model *Model
+	name  string
+}
+
+// 按外键的依赖关系对 models 进行排序，被引用的表在前，其它的保持原有的顺序。
+//
+// 引用了不在 models 中的表或是引用自身的外键会被忽略。
+// 存在循环引用时，导致循环的外键不参与排序，并通过 cycles 返回。
+func sortModels(models []*Model) (sorted []*Model, cycles []cycleFK) {
+	names := make(map[string]*Model, len(models))
+	for _, m := range models {
+		names[m.Name] = m
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*Model]int, len(models))
+	sorted = make([]*Model, 0, len(models))
+
+	var visit func(m *Model)
+	visit = func(m *Model) {
+		state[m] = visiting
+		for _, name := range fkNames(m) {
+			dep, found := names[refTableName(m.FK[name].RefTableName)]
+			if !found || dep == m {
+				continue
+			}
+
+			switch state[dep] {
+			case visiting:
+				cycles = append(cycles, cycleFK{model: m, name: name})
+			case visited:
+			default:
+				visit(dep)
+			}
+		}
+		state[m] = visited
+		sorted = append(sorted, m)
+	}
+
+	for _, m := range models {
+		if state[m] == 0 {
+			visit(m)
+		}
+	}
+
+	return sorted, cycles
+}
+
+// 返回 m 的所有外键名称，按名称排序。
+func fkNames(m *Model) []string {
+	keys := make([]string, 0, len(m.FK))
+	for name := range m.FK {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 去掉外键中引用表名的表名前缀占位符以及引号占位符，
+// 比如 {#groups} 和 #groups 都将返回 groups。
+func refTableName(name string) string {
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "}"), "{")
+	return strings.TrimPrefix(name, "#")
+}
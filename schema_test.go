@@ -0,0 +1,67 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+type cycleA struct {
+	ID int64 `orm:"name(id);pk"`
+	B  int64 `orm:"name(b);fk(fk_a_b,#cycle_b,id)"`
+}
+
+func (m *cycleA) Meta() string { return "name(cycle_a)" }
+
+type cycleB struct {
+	ID int64 `orm:"name(id);pk"`
+	A  int64 `orm:"name(a);fk(fk_b_a,#cycle_a,id)"`
+}
+
+func (m *cycleB) Meta() string { return "name(cycle_b)" }
+
+func TestWriteSchema(t *testing.T) {
+	a := assert.New(t)
+
+	buf := new(bytes.Buffer)
+	err := orm.WriteSchema(buf, dialect.Sqlite3(), "p_", &modeltest.Admin{}, &modeltest.UserInfo{}, &modeltest.Group{})
+	a.NotError(err)
+	script := buf.String()
+
+	// groups 被 administrators 引用，需要在其之前创建
+	groups := strings.Index(script, "CREATE TABLE IF NOT EXISTS `p_groups`")
+	admins := strings.Index(script, "CREATE TABLE IF NOT EXISTS `p_administrators`")
+	info := strings.Index(script, "CREATE TABLE IF NOT EXISTS `p_user_info`")
+	a.True(groups >= 0).True(admins > groups).True(info > admins)
+	a.Contains(script, "REFERENCES p_groups")
+	a.Contains(script, "-- p_groups\n")
+	a.NotContains(script, "#").NotContains(script, "{")
+
+	// 循环引用，sqlite3 直接在创建表时指定外键
+	buf.Reset()
+	err = orm.WriteSchema(buf, dialect.Sqlite3(), "p_", &cycleA{}, &cycleB{})
+	a.NotError(err)
+	script = buf.String()
+	a.Contains(script, "CONSTRAINT fk_a_b").Contains(script, "CONSTRAINT fk_b_a")
+	a.NotContains(script, "ALTER TABLE")
+
+	// 循环引用，mysql 通过 ALTER TABLE 添加外键
+	buf.Reset()
+	err = orm.WriteSchema(buf, dialect.Mysql(), "p_", &cycleA{}, &cycleB{})
+	a.NotError(err)
+	script = buf.String()
+	cycleB := strings.Index(script, "CREATE TABLE IF NOT EXISTS `p_cycle_b`")
+	cycleA := strings.Index(script, "CREATE TABLE IF NOT EXISTS `p_cycle_a`")
+	a.True(cycleB >= 0).True(cycleA > cycleB)
+	a.Contains(script, "CONSTRAINT fk_a_b").Equal(strings.Count(script, "fk_b_a"), 1)
+	a.Contains(script, "\n-- foreign keys\nALTER TABLE `p_cycle_b` ADD CONSTRAINT fk_b_a FOREIGN KEY(`a`) REFERENCES p_cycle_a(`id`);\n")
+}
@@ -0,0 +1,260 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+// Package sharding 提供了将同一个模型的数据按分片键分布到多个 orm.DB 的功能。
+//
+// 分片键通过 orm.Metaer 接口中的 shard 属性指定，其值为列名：
+//  func (u *User) Meta() string {
+//      return "name(users);shard(tenant_id)"
+//  }
+//
+// 各个分片可以是不同的数据库，也可以是同一个数据库中使用不同表名前缀的表，
+// 后者可以通过 PrefixShards() 构建，各个分片共用同一个连接池。
+package sharding
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+
+	"github.com/issue9/orm"
+)
+
+// ErrNoShardKey 表示模型未通过 shard 属性指定分片键。
+var ErrNoShardKey = errors.New("模型未指定分片键")
+
+// ErrZeroShardKey 表示分片键为零值，无法确定数据所在的分片。
+var ErrZeroShardKey = errors.New("分片键为零值")
+
+// ShardFunc 根据分片键的值 key 计算其所在分片的索引，返回值必须在 [0, shards) 之间。
+type ShardFunc func(key interface{}, shards int) int
+
+// Hash 默认的 ShardFunc，根据 key 的字符串形式的 fnv 哈希值计算分片。
+func Hash(key interface{}, shards int) int {
+	h := fnv.New32a()
+	fmt.Fprint(h, key)
+	return int(h.Sum32() % uint32(shards))
+}
+
+// Router 分片路由
+//
+// 所有操作均根据模型中分片键的值选择相应的分片执行；
+// 若分片键为零值，则 Select 和 Count 会在所有分片中查询，
+// 而 Insert、LastInsertID、Update 和 Delete 会返回 ErrZeroShardKey。
+// 各分片的自增 ID 是相互独立的，同一个 ID 在不同分片中可能表示不同的数据，
+// 所以写操作不会在所有分片中执行。
+type Router struct {
+	shards []*orm.DB
+	shard  ShardFunc
+}
+
+// New 声明一个新的 Router 实例
+//
+// f 为空，则采用 Hash 作为分片函数。
+func New(shards []*orm.DB, f ShardFunc) (*Router, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("参数 shards 不能为空")
+	}
+
+	if f == nil {
+		f = Hash
+	}
+
+	return &Router{
+		shards: shards,
+		shard:  f,
+	}, nil
+}
+
+// PrefixShards 在同一个数据库中，以不同的表名前缀构建多个分片。
+//
+// 返回的各个 orm.DB 共用 db 这一个连接池，关闭其中任意一个，其它的也将失效。
+func PrefixShards(db *sql.DB, dialect orm.Dialect, prefixes ...string) ([]*orm.DB, error) {
+	shards := make([]*orm.DB, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		shard, err := orm.NewDBWithStdDB(db, prefix, dialect)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+
+	return shards, nil
+}
+
+// Shards 返回所有的分片
+func (r *Router) Shards() []*orm.DB {
+	return r.shards
+}
+
+// ShardByKey 返回分片键的值为 key 的数据所在的分片
+func (r *Router) ShardByKey(key interface{}) *orm.DB {
+	return r.shards[r.shard(key, len(r.shards))]
+}
+
+// Shard 根据 v 中分片键的值返回其所在的分片
+//
+// 分片键为零值时返回 ErrZeroShardKey。
+func (r *Router) Shard(v interface{}) (*orm.DB, error) {
+	key, zero, err := shardKey(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if zero {
+		return nil, ErrZeroShardKey
+	}
+
+	return r.ShardByKey(key), nil
+}
+
+// 获取 v 中分片键的值，zero 表示该值是否为零值。
+func shardKey(v interface{}) (key interface{}, zero bool, err error) {
+	m, err := orm.NewModel(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	names, found := m.Meta["shard"]
+	if !found || len(names) != 1 {
+		return nil, false, ErrNoShardKey
+	}
+
+	col, found := m.Cols[names[0]]
+	if !found {
+		return nil, false, fmt.Errorf("分片键 %s 不存在", names[0])
+	}
+
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		rval = rval.Elem()
+	}
+
+	field := rval.FieldByName(col.GoName)
+	if !field.IsValid() {
+		return nil, false, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+	}
+
+	return field.Interface(), col.IsZero(field), nil
+}
+
+// Insert 将 v 插入到其分片键所对应的分片中
+func (r *Router) Insert(v interface{}) (sql.Result, error) {
+	db, err := r.Shard(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Insert(v)
+}
+
+// LastInsertID 将 v 插入到其分片键所对应的分片中，并返回其自增 ID。
+//
+// 不同分片的自增 ID 是相互独立的。
+func (r *Router) LastInsertID(v interface{}) (int64, error) {
+	db, err := r.Shard(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return db.LastInsertID(v)
+}
+
+// Update 更新其分片键所对应的分片中的数据
+//
+// 分片键为零值时返回 ErrZeroShardKey。
+func (r *Router) Update(v interface{}, cols ...string) (sql.Result, error) {
+	db, err := r.Shard(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Update(v, cols...)
+}
+
+// Delete 删除其分片键所对应的分片中的数据
+//
+// 分片键为零值时返回 ErrZeroShardKey。
+func (r *Router) Delete(v interface{}) (sql.Result, error) {
+	db, err := r.Shard(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Delete(v)
+}
+
+// Select 查询数据
+//
+// 若分片键为零值，则依次在各个分片中查找，并以第一个存在符合条件数据的分片为准，
+// 所有分片中都不存在时返回 orm.ErrNoRows。
+func (r *Router) Select(v interface{}) error {
+	key, zero, err := shardKey(v)
+	if err != nil {
+		return err
+	}
+
+	if !zero {
+		return r.ShardByKey(key).Select(v)
+	}
+
+	for _, db := range r.shards {
+		err := db.Select(v)
+		if errors.Is(err, orm.ErrNoRows) { // 数据可能在其它分片中
+			continue
+		}
+		return err
+	}
+
+	return orm.ErrNoRows
+}
+
+// Count 查询符合 v 条件的记录数量
+//
+// 若分片键为零值，则返回所有分片的数量之和。
+func (r *Router) Count(v interface{}) (int64, error) {
+	key, zero, err := shardKey(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if !zero {
+		return r.ShardByKey(key).Count(v)
+	}
+
+	var total int64
+	for _, db := range r.shards {
+		cnt, err := db.Count(v)
+		if err != nil {
+			return 0, err
+		}
+		total += cnt
+	}
+
+	return total, nil
+}
+
+// MultCreate 在所有分片中创建数据表
+func (r *Router) MultCreate(objs ...interface{}) error {
+	for _, db := range r.shards {
+		if err := db.MultCreate(objs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MultDrop 删除所有分片中的数据表
+func (r *Router) MultDrop(objs ...interface{}) error {
+	for _, db := range r.shards {
+		if err := db.MultDrop(objs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
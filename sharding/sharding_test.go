@@ -0,0 +1,150 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package sharding
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const testDBFile = "./test.db"
+
+type order struct {
+	ID     int64  `orm:"name(id);pk"`
+	Tenant int64  `orm:"name(tenant_id)"`
+	Title  string `orm:"name(title);len(50)"`
+}
+
+func (o *order) Meta() string {
+	return "name(orders);shard(tenant_id)"
+}
+
+type noShard struct {
+	ID int64 `orm:"name(id);pk"`
+}
+
+// 以两个不同的表名前缀作为分片
+func newRouter(a *assert.Assertion) *Router {
+	db, err := sql.Open("sqlite3", testDBFile)
+	a.NotError(err)
+
+	shards, err := PrefixShards(db, dialect.Sqlite3(), "s0_", "s1_")
+	a.NotError(err).Equal(len(shards), 2)
+
+	r, err := New(shards, func(key interface{}, shards int) int {
+		return int(key.(int64) % int64(shards))
+	})
+	a.NotError(err).NotNil(r)
+	a.NotError(r.MultCreate(&order{}))
+
+	return r
+}
+
+func closeRouter(a *assert.Assertion, r *Router) {
+	a.NotError(r.MultDrop(&order{}))
+	a.NotError(r.Shards()[0].Close())
+	a.NotError(os.Remove(testDBFile))
+}
+
+func TestHash(t *testing.T) {
+	a := assert.New(t)
+
+	for _, key := range []interface{}{1, int64(5), "tenant", 3.5} {
+		i := Hash(key, 3)
+		a.True(i >= 0 && i < 3)
+		a.Equal(i, Hash(key, 3))
+	}
+}
+
+func TestNew(t *testing.T) {
+	a := assert.New(t)
+
+	r, err := New(nil, nil)
+	a.Error(err).Nil(r)
+
+	r, err = New([]*orm.DB{{}}, nil)
+	a.NotError(err).NotNil(r)
+	a.NotNil(r.shard)
+}
+
+func TestRouter(t *testing.T) {
+	a := assert.New(t)
+	r := newRouter(a)
+	defer closeRouter(a, r)
+
+	for i := int64(1); i <= 6; i++ {
+		_, err := r.Insert(&order{ID: i, Tenant: i, Title: "t"})
+		a.NotError(err)
+	}
+
+	// 按分片键路由
+	cnt, err := r.Shards()[0].Count(&order{Title: "t"})
+	a.NotError(err).Equal(cnt, 3)
+	cnt, err = r.Count(&order{Tenant: 3})
+	a.NotError(err).Equal(cnt, 1)
+
+	// 分片键为零值，查询所有分片
+	cnt, err = r.Count(&order{Title: "t"})
+	a.NotError(err).Equal(cnt, 6)
+
+	o := &order{ID: 5}
+	a.NotError(r.Select(o))
+	a.Equal(o.Tenant, 5).Equal(o.Title, "t")
+
+	o = &order{ID: 4, Tenant: 4}
+	a.NotError(r.Select(o))
+	a.Equal(o.Title, "t")
+
+	a.Equal(r.Select(&order{ID: 100}), orm.ErrNoRows)
+	a.Equal(r.Select(&order{ID: 100, Tenant: 1}), orm.ErrNoRows)
+
+	// update
+	rslt, err := r.Update(&order{ID: 2, Tenant: 2, Title: "t2"})
+	a.NotError(err)
+	n, err := rslt.RowsAffected()
+	a.NotError(err).Equal(n, 1)
+
+	o = &order{ID: 2, Tenant: 2}
+	a.NotError(r.Select(o))
+	a.Equal(o.Title, "t2")
+
+	// delete
+	rslt, err = r.Delete(&order{ID: 3, Tenant: 3})
+	a.NotError(err)
+	n, err = rslt.RowsAffected()
+	a.NotError(err).Equal(n, 1)
+
+	cnt, err = r.Count(&order{Title: "t"})
+	a.NotError(err).Equal(cnt, 4)
+
+	// 分片键为零值，不会修改所有分片中 ID 相同的数据
+	_, err = r.Update(&order{ID: 1, Title: "changed"})
+	a.Equal(err, ErrZeroShardKey)
+	_, err = r.Delete(&order{ID: 1})
+	a.Equal(err, ErrZeroShardKey)
+	o = &order{ID: 1, Tenant: 1}
+	a.NotError(r.Select(o))
+	a.Equal(o.Title, "t")
+
+	// 未指定分片键
+	_, err = r.Insert(&noShard{ID: 1})
+	a.Equal(err, ErrNoShardKey)
+
+	// 分片键为零值，无法插入
+	_, err = r.Insert(&order{ID: 7, Title: "t"})
+	a.Equal(err, ErrZeroShardKey)
+	_, err = r.LastInsertID(&order{ID: 7, Title: "t"})
+	a.Equal(err, ErrZeroShardKey)
+	cnt, err = r.Count(&order{Title: "t"})
+	a.NotError(err).Equal(cnt, 4)
+}
+
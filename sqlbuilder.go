@@ -8,8 +8,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 
+	"github.com/issue9/orm/fetch"
 	"github.com/issue9/orm/sqlbuilder"
 )
 
@@ -28,31 +31,46 @@ func getModel(v interface{}) (*Model, reflect.Value, error) {
 }
 
 // 根据 Model 中的主键或是唯一索引为 sql 产生 where 语句，
-// 若两者都不存在，则返回错误信息。rval 为 struct 的 reflect.Value
-func where(sb sqlbuilder.WhereStmter, m *Model, rval reflect.Value) error {
+// 若两者都不存在，则返回错误信息。cv 为对象各列的值
+//
+// 若 e 限定了租户，还会加上租户列的条件。
+func where(e Engine, sb sqlbuilder.WhereStmter, m *Model, cv *columnValues) error {
 	vals := make([]interface{}, 0, 3)
 	keys := make([]string, 0, 3)
 
 	// 获取构成 where 的键名和键值
-	getKV := func(cols []*Column) bool {
-		for _, col := range cols {
-			field := rval.FieldByName(col.GoName)
-
-			if col.IsZero(field) {
-				vals = vals[:0]
-				keys = keys[:0]
-				return false
+	getKV := func(cols []*Column, colVals []interface{}) bool {
+		for i, col := range cols {
+			if !col.isZeroValue(colVals[i]) {
+				keys = append(keys, col.Name)
+				vals = append(vals, colVals[i])
+				continue
 			}
 
-			keys = append(keys, col.Name)
-			vals = append(vals, field.Interface())
+			vals = vals[:0]
+			keys = keys[:0]
+			return false
 		}
 		return len(keys) > 0 // 如果 keys 中有数据，表示已经采集成功，否则表示 cols 的长度为 0
 	}
 
-	if !getKV(m.PK) { // 没有主键，则尝试唯一约束
+	pk, err := cv.pk()
+	if err != nil {
+		return err
+	}
+
+	if !getKV(m.PK, pk) { // 没有主键，则尝试唯一约束
 		for _, cols := range m.UniqueIndexes {
-			if getKV(cols) {
+			colVals := make([]interface{}, 0, len(cols))
+			for _, col := range cols {
+				val, err := cv.get(col)
+				if err != nil {
+					return err
+				}
+				colVals = append(colVals, val)
+			}
+
+			if getKV(cols, colVals) {
 				break
 			}
 		}
@@ -66,26 +84,37 @@ func where(sb sqlbuilder.WhereStmter, m *Model, rval reflect.Value) error {
 		sb.WhereStmt().And("{"+key+"}=?", vals[index])
 	}
 
-	return nil
+	_, err = scopeWhere(e, sb, m, cv.rval)
+	return err
 }
 
-// 根据 rval 中任意非零值产生 where 语句
-func whereAny(sb sqlbuilder.WhereStmter, m *Model, rval reflect.Value) error {
+// 根据 cv 中任意非零值产生 where 语句
+//
+// 若 e 限定了租户，还会加上租户列的条件，此时允许 cv 中不包含非零值。
+func whereAny(e Engine, sb sqlbuilder.WhereStmter, m *Model, cv *columnValues) error {
 	vals := make([]interface{}, 0, 3)
 	keys := make([]string, 0, 3)
 
+	scoped, err := scopeWhere(e, sb, m, cv.rval)
+	if err != nil {
+		return err
+	}
+
 	for _, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
+		val, err := cv.get(col)
+		if err != nil {
+			return err
+		}
 
-		if col.IsZero(field) {
+		if col.isZeroValue(val) || (scoped && col == m.Tenant) {
 			continue
 		}
 
 		keys = append(keys, col.Name)
-		vals = append(vals, field.Interface())
+		vals = append(vals, val)
 	}
 
-	if len(keys) == 0 {
+	if len(keys) == 0 && !scoped {
 		return fmt.Errorf("没有非零值字段，无法为 %s 产生 where 部分语句", m.Name)
 	}
 
@@ -97,14 +126,17 @@ func whereAny(sb sqlbuilder.WhereStmter, m *Model, rval reflect.Value) error {
 }
 
 // 统计符合 v 条件的记录数量。
-func count(e Engine, v interface{}) (int64, error) {
+func count(e Engine, v interface{}) (cnt int64, err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return 0, err
 	}
 
+	e, span := traceModel(e, "count", m)
+	defer func() { endSpan(span, -1, err) }()
+
 	sql := e.SQL().Select().Count("COUNT(*) AS count").From("{#" + m.Name + "}")
-	if err = whereAny(sql, m, rval); err != nil {
+	if err = whereAny(e, sql, m, newColumnValues(m, v, rval)); err != nil {
 		return 0, err
 	}
 
@@ -121,6 +153,13 @@ func create(e Engine, v interface{}) error {
 		return err
 	}
 
+	return createModel(e, m)
+}
+
+func createModel(e Engine, m *Model) (err error) {
+	e, span := traceModel(e, "create", m)
+	defer func() { endSpan(span, -1, err) }()
+
 	sqls, err := e.Dialect().CreateTableSQL(m)
 	if err != nil {
 		return err
@@ -142,16 +181,26 @@ func drop(e Engine, v interface{}) error {
 		return err
 	}
 
+	return dropModel(e, m)
+}
+
+func dropModel(e Engine, m *Model) (err error) {
+	e, span := traceModel(e, "drop", m)
+	defer func() { endSpan(span, -1, err) }()
+
 	_, err = e.SQL().DropTable().Table("{#" + m.Name + "}").Exec()
 	return err
 }
 
-func lastInsertID(e Engine, v interface{}) (int64, error) {
+func lastInsertID(e Engine, v interface{}) (id int64, err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return 0, err
 	}
 
+	e, span := traceModel(e, "insert", m)
+	defer func() { endSpan(span, -1, err) }()
+
 	if m.AI == nil {
 		return 0, errors.New("该对象并没有自增列")
 	}
@@ -162,49 +211,67 @@ func lastInsertID(e Engine, v interface{}) (int64, error) {
 		}
 	}
 
+	if err = applyTenant(e, m, rval); err != nil {
+		return 0, err
+	}
+
 	sql := e.SQL().Insert().Table("{#" + m.Name + "}")
+	cv := newColumnValues(m, v, rval)
 	for name, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
-		if !field.IsValid() {
-			return 0, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+		val, err := cv.get(col)
+		if err != nil {
+			return 0, err
 		}
 
 		// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-		if col.IsZero(field) && (col.IsAI() || col.HasDefault) {
+		if col.isZeroValue(val) && (col.IsAI() || col.HasDefault) {
 			continue
 		}
 
-		sql.KeyValue("{"+name+"}", field.Interface())
+		sql.KeyValue("{"+name+"}", val)
 	}
 
-	return sql.LastInsertID(m.Name, m.AI.Name)
+	id, err = sql.LastInsertID(m.Name, m.AI.Name)
+	if err != nil {
+		// 部分数据库通过 QueryRow 获取 ID，其错误未经转换。
+		return 0, e.Dialect().TranslateError(err)
+	}
+	return id, nil
 }
 
-func insert(e Engine, v interface{}) (sql.Result, error) {
+func insert(e Engine, v interface{}) (r sql.Result, err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return nil, err
 	}
 
+	e, span := traceModel(e, "insert", m)
+	defer func() { endSpan(span, rowsAffected(r), err) }()
+
 	if obj, ok := v.(BeforeInserter); ok {
 		if err = obj.BeforeInsert(); err != nil {
 			return nil, err
 		}
 	}
 
+	if err = applyTenant(e, m, rval); err != nil {
+		return nil, err
+	}
+
 	sql := e.SQL().Insert().Table("{#" + m.Name + "}")
+	cv := newColumnValues(m, v, rval)
 	for name, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
-		if !field.IsValid() {
-			return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+		val, err := cv.get(col)
+		if err != nil {
+			return nil, err
 		}
 
 		// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-		if col.IsZero(field) && (col.IsAI() || col.HasDefault) {
+		if col.isZeroValue(val) && (col.IsAI() || col.HasDefault) {
 			continue
 		}
 
-		sql.KeyValue("{"+name+"}", field.Interface())
+		sql.KeyValue("{"+name+"}", val)
 	}
 
 	return sql.Exec()
@@ -214,30 +281,37 @@ func insert(e Engine, v interface{}) (sql.Result, error) {
 //
 // 根据 v 的 pk 或中唯一索引列查找一行数据，并赋值给 v。
 // 若 v 为空，则不发生任何操作，v 可以是数组。
-func find(e Engine, v interface{}) error {
+// 未找到数据时返回 ErrNoRows，除非 e 指定了 IgnoreNotFound。
+func find(e Engine, v interface{}) (err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return err
 	}
 
+	e, span := traceModel(e, "select", m)
+	defer func() { endSpan(span, -1, err) }()
+
 	sql := e.SQL().Select().
 		Select("*").
 		From("{#" + m.Name + "}")
-	if err = where(sql, m, rval); err != nil {
+	if err = where(e, sql, m, newColumnValues(m, v, rval)); err != nil {
 		return err
 	}
 
-	_, err = sql.QueryObj(v)
-	return err
+	return queryFound(e, sql, v)
 }
 
 // for update 只能作用于事务
-func forUpdate(tx *Tx, v interface{}) error {
+func forUpdate(tx *Tx, v interface{}, lock ...*sqlbuilder.Lock) (err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return err
 	}
 
+	e, span := traceModel(tx, "select", m)
+	tx = e.(*Tx)
+	defer func() { endSpan(span, -1, err) }()
+
 	if obj, ok := v.(BeforeUpdater); ok {
 		if err = obj.BeforeUpdate(); err != nil {
 			return err
@@ -248,12 +322,29 @@ func forUpdate(tx *Tx, v interface{}) error {
 		Select("*").
 		From("{#" + m.Name + "}").
 		ForUpdate()
-	if err = where(sql, m, rval); err != nil {
+	if len(lock) > 0 && lock[0] != nil {
+		sql.Lock(lock[0])
+	}
+	if err = where(tx, sql, m, newColumnValues(m, v, rval)); err != nil {
 		return err
 	}
 
-	_, err = sql.QueryObj(v)
-	return err
+	return queryFound(tx, sql, v)
+}
+
+// 将 sql 的查询结果写入 v，未找到数据时返回 ErrNoRows。
+func queryFound(e Engine, sql *sqlbuilder.SelectStmt, v interface{}) error {
+	n, err := sql.QueryObj(v)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		if db := dbOf(e); db == nil || !db.ignoreNotFound {
+			return ErrNoRows
+		}
+	}
+	return nil
 }
 
 // 更新 v 到数据库，默认情况下不更新零值。
@@ -261,48 +352,92 @@ func forUpdate(tx *Tx, v interface{}) error {
 //
 // 更新依据为每个对象的主键或是唯一索引列。
 // 若不存在此两个类型的字段，则返回错误信息。
-func update(e Engine, v interface{}, cols ...string) (sql.Result, error) {
+func update(e Engine, v interface{}, cols ...string) (r sql.Result, err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return nil, err
 	}
 
+	e, span := traceModel(e, "update", m)
+	defer func() { endSpan(span, rowsAffected(r), err) }()
+
 	if obj, ok := v.(BeforeUpdater); ok {
 		if err = obj.BeforeUpdate(); err != nil {
 			return nil, err
 		}
 	}
 
+	tv, err := tenantValue(e, m)
+	if err != nil {
+		return nil, err
+	}
+
 	sql := e.SQL().Update().Table("{#" + m.Name + "}")
+	cv := newColumnValues(m, v, rval)
 	var occValue interface{}
 	for name, col := range m.Cols {
-		field := rval.FieldByName(col.GoName)
-		if !field.IsValid() {
-			return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+		val, err := cv.get(col)
+		if err != nil {
+			return nil, err
 		}
 
-		// 零值，但是不属于指定需要更新的列
-		if !inStrSlice(name, cols) && col.IsZero(field) {
+		// 限定了租户时，不允许修改租户列
+		if tv.IsValid() && col == m.Tenant {
 			continue
 		}
 
-		if m.OCC == col { // 乐观锁
-			occValue = field.Interface()
+		if m.OCC == col { // 乐观锁，零值也是有效的版本号
+			occValue = val
+			continue
+		}
+
+		// 零值，但是不属于指定需要更新的列
+		if !inStrSlice(name, cols) && col.isZeroValue(val) {
 			continue
-		} else {
-			sql.Set("{"+name+"}", field.Interface())
 		}
+
+		sql.Set("{"+name+"}", val)
 	}
 
 	if m.OCC != nil {
 		sql.OCC("{"+m.OCC.Name+"}", occValue)
 	}
 
-	if err := where(sql, m, rval); err != nil {
+	if err := where(e, sql, m, cv); err != nil {
 		return nil, err
 	}
 
-	return sql.Exec()
+	r, err = sql.Exec()
+	if err != nil || m.OCC == nil {
+		return r, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrOptimisticLock
+	}
+
+	increaseOCC(rval.FieldByName(m.OCC.GoName))
+	return r, nil
+}
+
+// 将乐观锁字段的值加 1，以保持与数据库中的值一致。
+func increaseOCC(field reflect.Value) {
+	if !field.CanSet() {
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	case reflect.Float32, reflect.Float64:
+		field.SetFloat(field.Float() + 1)
+	}
 }
 
 func inStrSlice(key string, slice []string) bool {
@@ -315,25 +450,47 @@ func inStrSlice(key string, slice []string) bool {
 }
 
 // 将 v 生成 delete 的 sql 语句
-func del(e Engine, v interface{}) (sql.Result, error) {
+func del(e Engine, v interface{}) (r sql.Result, err error) {
 	m, rval, err := getModel(v)
 	if err != nil {
 		return nil, err
 	}
 
+	e, span := traceModel(e, "delete", m)
+	defer func() { endSpan(span, rowsAffected(r), err) }()
+
 	sql := e.SQL().Delete().Table("{#" + m.Name + "}")
-	if err = where(sql, m, rval); err != nil {
+	if err = where(e, sql, m, newColumnValues(m, v, rval)); err != nil {
 		return nil, err
 	}
 
-	return sql.Exec()
+	r, err = sql.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	if db := dbOf(e); db == nil || !db.checkAffected {
+		return r, nil
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrNoRows
+	}
+	return r, nil
 }
 
 // rval 为结构体指针组成的数据
-func buildInsertManySQL(e *Tx, rval reflect.Value) (*sqlbuilder.InsertStmt, error) {
-	sql := e.SQL().Insert()
-	keys := []string{}         // 保存列的顺序，方便后续元素获取值
+//
+// 根据 Dialect.MaxArgs() 的值，数据可能会被拆分成多条插入语句。
+func buildInsertManySQL(e *Tx, rval reflect.Value) ([]*sqlbuilder.InsertStmt, *Model, error) {
+	var m *Model
 	var firstType reflect.Type // 记录数组中第一个元素的类型，保证后面的都相同
+	keys := []string{}         // 保存列的顺序，方便后续元素获取值
+	rows := make([][]interface{}, 0, rval.Len())
 
 	for i := 0; i < rval.Len(); i++ {
 		irval := rval.Index(i)
@@ -341,60 +498,352 @@ func buildInsertManySQL(e *Tx, rval reflect.Value) (*sqlbuilder.InsertStmt, erro
 		// 判断 beforeInsert
 		if obj, ok := irval.Interface().(BeforeInserter); ok {
 			if err := obj.BeforeInsert(); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
-		m, irval, err := getModel(irval.Interface())
+		obj := irval.Interface()
+		im, irval, err := getModel(obj)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if err = applyTenant(e, im, irval); err != nil {
+			return nil, nil, err
 		}
 
 		if i == 0 { // 第一个元素，需要从中获取列信息。
+			m = im
 			firstType = irval.Type()
-			sql.Table("{#" + m.Name + "}")
 
+			cv := newColumnValues(im, obj, irval)
+			vals := make([]interface{}, 0, len(m.Cols))
 			for name, col := range m.Cols {
-				field := irval.FieldByName(col.GoName)
-				if !field.IsValid() {
-					return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+				val, err := cv.get(col)
+				if err != nil {
+					return nil, nil, err
 				}
 
 				// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-				if col.IsZero(field) && (col.IsAI() || col.HasDefault) {
+				if col.isZeroValue(val) && (col.IsAI() || col.HasDefault) {
 					continue
 				}
 
-				sql.KeyValue("{"+name+"}", field.Interface())
+				vals = append(vals, val)
 				keys = append(keys, name)
 			}
+			rows = append(rows, vals)
 		} else { // 之后的元素，只需要获取其对应的值就行
 			if firstType != irval.Type() { // 与第一个元素的类型不同。
-				return nil, errors.New("参数 v 中包含了不同类型的元素")
+				return nil, nil, errors.New("参数 v 中包含了不同类型的元素")
 			}
 
+			cv := newColumnValues(im, obj, irval)
 			vals := make([]interface{}, 0, len(keys))
 			for _, name := range keys {
 				col, found := m.Cols[name]
 				if !found {
-					return nil, fmt.Errorf("不存在的列名 %s", name)
+					return nil, nil, fmt.Errorf("不存在的列名 %s", name)
 				}
 
-				field := irval.FieldByName(col.GoName)
-				if !field.IsValid() {
-					return nil, fmt.Errorf("未找到该名称 %s 的值", col.GoName)
+				val, err := cv.get(col)
+				if err != nil {
+					return nil, nil, err
 				}
 
 				// 在为零值的情况下，若该列是 AI 或是有默认值，则过滤掉。无论该零值是否为手动设置的。
-				if col.IsZero(field) && (col.IsAI() || col.HasDefault) {
+				if col.isZeroValue(val) && (col.IsAI() || col.HasDefault) {
 					continue
 				}
 
-				vals = append(vals, field.Interface())
+				vals = append(vals, val)
 			}
-			sql.Values(vals...)
+			rows = append(rows, vals)
 		}
 	} // end for array
 
-	return sql, nil
+	if len(rows) == 0 {
+		return nil, nil, sqlbuilder.ErrValueIsEmpty
+	}
+
+	cols := make([]string, 0, len(keys))
+	for _, name := range keys {
+		cols = append(cols, "{"+name+"}")
+	}
+
+	size := batchSize(e.Dialect(), len(cols))
+	stmts := make([]*sqlbuilder.InsertStmt, 0, len(rows)/size+1)
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		sql := e.SQL().Insert().Table("{#" + m.Name + "}").Columns(cols...)
+		for _, vals := range rows[start:end] {
+			sql.Values(vals...)
+		}
+		stmts = append(stmts, sql)
+	}
+
+	return stmts, m, nil
+}
+
+// 根据 Dialect.MaxArgs() 计算每条语句中可以包含的记录数量，
+// argsPerRow 表示每条记录需要的参数数量。
+func batchSize(d Dialect, argsPerRow int) int {
+	max := d.MaxArgs()
+	if max <= 0 || argsPerRow <= 0 {
+		return math.MaxInt32
+	}
+
+	if size := max / argsPerRow; size > 0 {
+		return size
+	}
+	return 1
+}
+
+// 获取数组 rval 中每个元素 cols 列的值，所有元素的类型必须与第一个元素相同。
+//
+// update 为 true 时，会调用每个元素的 BeforeUpdate()，
+// 若 e 限定了租户，还会为租户列为零值的元素设置租户值。
+func getManyValues(e Engine, m *Model, rval reflect.Value, cols []*Column, update bool) ([][]interface{}, error) {
+	rows := make([][]interface{}, 0, rval.Len())
+	var firstType reflect.Type
+
+	tv, err := tenantValue(e, m)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < rval.Len(); i++ {
+		obj := rval.Index(i).Interface()
+
+		if obj, ok := obj.(BeforeUpdater); ok && update {
+			if err := obj.BeforeUpdate(); err != nil {
+				return nil, err
+			}
+		}
+
+		irval := reflect.ValueOf(obj)
+		for irval.Kind() == reflect.Ptr {
+			irval = irval.Elem()
+		}
+
+		if i == 0 {
+			firstType = irval.Type()
+		} else if firstType != irval.Type() {
+			return nil, errors.New("参数 v 中包含了不同类型的元素")
+		}
+
+		if tv.IsValid() {
+			if err := checkTenant(m, irval, tv, update); err != nil {
+				return nil, err
+			}
+		}
+
+		vals := make([]interface{}, 0, len(cols))
+		cv := newColumnValues(m, obj, irval)
+		for _, col := range cols {
+			val, err := cv.get(col)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		rows = append(rows, vals)
+	}
+
+	return rows, nil
+}
+
+// 获取数组 v 的第一个元素的 Model，v 为空时返回的 Model 为 nil。
+func getManyModel(v interface{}) (*Model, reflect.Value, error) {
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		rval = rval.Elem()
+	}
+
+	if rval.Kind() != reflect.Array && rval.Kind() != reflect.Slice {
+		return nil, rval, fetch.ErrInvalidKind
+	}
+
+	if rval.Len() == 0 {
+		return nil, rval, nil
+	}
+
+	m, _, err := getModel(rval.Index(0).Interface())
+	if err != nil {
+		return nil, rval, err
+	}
+
+	if len(m.PK) == 0 {
+		return nil, rval, fmt.Errorf("没有主键，无法为 %s 产生 where 部分语句", m.Name)
+	}
+
+	return m, rval, nil
+}
+
+// 以一条 DELETE ... WHERE pk IN(...) 语句删除 v 中的所有元素，
+// 数据量过大时，会根据 Dialect.MaxArgs() 拆分成多条语句。
+//
+// v 必须为数组，且所有元素的类型相同，以主键作为删除的依据。
+func deleteMany(e Engine, v interface{}) (affected int64, err error) {
+	m, rval, err := getManyModel(v)
+	if err != nil || m == nil {
+		return 0, err
+	}
+
+	e, span := traceModel(e, "delete", m)
+	defer func() { endSpan(span, affected, err) }()
+
+	rows, err := getManyValues(e, m, rval, m.PK, false)
+	if err != nil {
+		return 0, err
+	}
+
+	tv, err := tenantValue(e, m)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	size := batchSize(e.Dialect(), len(m.PK))
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		sql := e.SQL().Delete().Table("{#" + m.Name + "}")
+		if e.Dialect().RowValues() {
+			sql.WhereStmt().AndIn(quoteColumns(m.PK), rows[start:end]...)
+		} else {
+			sql.WhereStmt().AndInExpand(quoteColumns(m.PK), rows[start:end]...)
+		}
+		if tv.IsValid() {
+			sql.WhereStmt().And("{"+m.Tenant.Name+"}=?", tv.Interface())
+		}
+		r, err := sql.Exec()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := r.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+// 以 Dialect.UpdateManySQL() 生成的语句同时更新 v 中的所有元素，
+// 数据量过大时，会根据 Dialect.MaxArgs() 拆分成多条语句。
+//
+// v 必须为数组，且所有元素的类型相同，以主键作为更新的依据。
+// cols 为需要更新的列，为空表示除主键之外的所有列，零值也会被更新。
+func updateMany(e Engine, v interface{}, cols ...string) (affected int64, err error) {
+	m, rval, err := getManyModel(v)
+	if err != nil || m == nil {
+		return 0, err
+	}
+
+	e, span := traceModel(e, "update", m)
+	defer func() { endSpan(span, affected, err) }()
+
+	tv, err := tenantValue(e, m)
+	if err != nil {
+		return 0, err
+	}
+
+	// 限定了租户时，租户列作为定位记录的列之一，且不能被更新。
+	keys := m.PK
+	if tv.IsValid() && !isPK(m, m.Tenant) {
+		keys = append(keys[:len(keys):len(keys)], m.Tenant)
+	}
+
+	ucols := make([]*Column, 0, len(m.Cols))
+	if len(cols) == 0 {
+		for _, col := range m.Cols {
+			if !isKey(keys, col) {
+				ucols = append(ucols, col)
+			}
+		}
+		sort.SliceStable(ucols, func(i, j int) bool { return ucols[i].Name < ucols[j].Name })
+	} else {
+		for _, name := range cols {
+			col, found := m.Cols[name]
+			if !found {
+				return 0, fmt.Errorf("不存在的列名 %s", name)
+			}
+			if isPK(m, col) {
+				return 0, fmt.Errorf("不能更新主键列 %s", name)
+			}
+			if isKey(keys, col) {
+				return 0, fmt.Errorf("不能更新租户列 %s", name)
+			}
+			ucols = append(ucols, col)
+		}
+	}
+	if len(ucols) == 0 {
+		return 0, sqlbuilder.ErrValueIsEmpty
+	}
+
+	all := make([]*Column, 0, len(keys)+len(ucols))
+	all = append(append(all, keys...), ucols...)
+	rows, err := getManyValues(e, m, rval, all, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	size := batchSize(e.Dialect(), (len(keys)+1)*len(ucols)+len(keys))
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		query, args, err := e.Dialect().UpdateManySQL(m, keys, ucols, rows[start:end])
+		if err != nil {
+			return 0, err
+		}
+
+		r, err := e.Exec(query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := r.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+func isPK(m *Model, col *Column) bool {
+	return isKey(m.PK, col)
+}
+
+func isKey(keys []*Column, col *Column) bool {
+	for _, key := range keys {
+		if key == col {
+			return true
+		}
+	}
+	return false
+}
+
+// 将列名转换成 {name} 的形式
+func quoteColumns(cols []*Column) []string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, "{"+col.Name+"}")
+	}
+	return names
 }
@@ -0,0 +1,207 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package sqlbuilder
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/issue9/orm/fetch"
+)
+
+// ErrInvalidCursor 表示传递给 Keyset 的 cursor 无法解析或是与列不匹配。
+var ErrInvalidCursor = errors.New("无效的 cursor")
+
+func init() {
+	// cursor 中的值已经被转换成 driver.Value，
+	// 除 time.Time 之外的类型 gob 均已默认注册。
+	gob.Register(time.Time{})
+}
+
+// Page 分页查询的结果信息
+type Page struct {
+	Page  int   // 当前页码，从 1 开始
+	Size  int   // 每页的记录数量
+	Count int   // 当前页实际的记录数量
+	Total int64 // 符合条件的记录总数
+	Pages int64 // 总页数
+}
+
+// HasNext 是否还存在下一页
+func (p *Page) HasNext() bool {
+	return int64(p.Page) < p.Pages
+}
+
+// Paginate 将第 page 页的数据写入 objs，并返回包含记录总数在内的分页信息。
+//
+// 会依次执行 COUNT(*) 和带 LIMIT 的查询两条语句，
+// 若需要两者的数据保持一致，可以使用由 Tx 生成的 SelectStmt。
+// 该操作会修改 stmt 的 LIMIT 部分，但不影响已经指定的 Count 表达式；
+// 对于包含 DISTINCT 或 GROUP BY 的语句，会以子查询的形式计算总数。
+//
+// page 从 1 开始，关于 objs 的值类型，可以参考 fetch.Object 函数的相关介绍。
+func (stmt *SelectStmt) Paginate(objs interface{}, page, size int) (*Page, error) {
+	if page < 1 || size < 1 {
+		return nil, errors.New("page 和 size 必须大于 0")
+	}
+
+	total, err := stmt.total()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Page{
+		Page:  page,
+		Size:  size,
+		Total: total,
+		Pages: (total + int64(size) - 1) / int64(size),
+	}
+
+	if int64((page-1)*size) >= total { // 超出范围，不需要再查询
+		return p, nil
+	}
+
+	stmt.Limit(size, (page-1)*size)
+	if p.Count, err = stmt.QueryObj(objs); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// 获取符合当前条件的记录总数
+func (stmt *SelectStmt) total() (int64, error) {
+	countExpr := stmt.countExpr
+	defer func() { stmt.countExpr = countExpr }()
+
+	if !stmt.distinct && stmt.group == "" {
+		stmt.countExpr = "COUNT(*) AS count"
+		return stmt.QueryInt("count")
+	}
+
+	// DISTINCT 和 GROUP BY 需要对整个结果集计数，
+	// 去掉 ORDER BY 和 LIMIT 之后作为子查询使用。
+	orders, limitQuery, limitVals := stmt.orders, stmt.limitQuery, stmt.limitVals
+	stmt.countExpr, stmt.orders, stmt.limitQuery, stmt.limitVals = "", nil, "", nil
+	query, args, err := stmt.SQL()
+	stmt.orders, stmt.limitQuery, stmt.limitVals = orders, limitQuery, limitVals
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := stmt.engine.Query("SELECT COUNT(*) AS count FROM ("+query+") AS t", args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	v, err := queryFloat(rows, "count")
+	if err != nil {
+		return 0, err
+	}
+	return int64(v), nil
+}
+
+// Keyset 键集(cursor)分页，将 cursor 之后的最多 size 条记录写入 objs。
+//
+// 相对于 Paginate，不需要通过 OFFSET 跳过之前的记录，适用于数据量很大的表。
+// cols 为排序及定位使用的列名，会被 {} 包含，所以只能是普通的列名，
+// 且这些列的值组合起来必须是唯一的，比如以主键作为最后一列；
+// 数据按 cols 正序排列，stmt 中不应该再指定其它的排序方式。
+//
+// cursor 为上一次调用返回的值，获取第一页时传递空字符串即可；
+// 返回值 next 为获取下一页时需要的 cursor，若已经没有更多的数据，则返回空字符串。
+//
+// objs 只能是指向切片的指针，该操作会修改 stmt 的 WHERE、ORDER BY 和 LIMIT 部分。
+func (stmt *SelectStmt) Keyset(objs interface{}, size int, cursor string, cols ...string) (next string, err error) {
+	if size < 1 {
+		return "", errors.New("size 必须大于 0")
+	}
+	if len(cols) == 0 {
+		return "", ErrColumnsIsEmpty
+	}
+
+	rval := reflect.ValueOf(objs)
+	if rval.Kind() != reflect.Ptr || rval.Elem().Kind() != reflect.Slice {
+		return "", fetch.ErrInvalidKind
+	}
+	rval = rval.Elem()
+
+	quoted := make([]string, 0, len(cols))
+	for _, col := range cols {
+		quoted = append(quoted, "{"+col+"}")
+	}
+
+	if cursor != "" {
+		vals, err := decodeCursor(cursor)
+		if err != nil {
+			return "", err
+		}
+		if len(vals) != len(cols) {
+			return "", ErrInvalidCursor
+		}
+
+		if len(cols) == 1 {
+			stmt.And(quoted[0]+">?", vals...)
+		} else {
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(vals)), ",")
+			stmt.And("("+strings.Join(quoted, ",")+")>("+placeholders+")", vals...)
+		}
+	}
+
+	// 多取一条记录，用于判断是否还有下一页。
+	stmt.Asc(quoted...).Limit(size + 1)
+	count, err := stmt.QueryObj(objs)
+	if err != nil {
+		return "", err
+	}
+	if count <= size {
+		// objs 可能是一个重复使用的切片，需要去掉之前遗留的数据。
+		rval.Set(rval.Slice(0, count))
+		return "", nil
+	}
+
+	rval.Set(rval.Slice(0, size))
+	vals, err := fetch.Values(rval.Index(size-1).Interface(), cols...)
+	if err != nil {
+		return "", err
+	}
+	return encodeCursor(vals)
+}
+
+func encodeCursor(vals []interface{}) (string, error) {
+	for i, v := range vals {
+		val, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			return "", err
+		}
+		vals[i] = val
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(vals); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var vals []interface{}
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&vals); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return vals, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package sqlbuilder_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+type pageUser struct {
+	ID    int64  `orm:"name(id)"`
+	Name  string `orm:"name(name)"`
+	Group int64  `orm:"name(group)"`
+}
+
+func initPageDB(a *assert.Assertion) *orm.DB {
+	db, err := orm.NewDB("sqlite3", "./test.db", "test_", dialect.Sqlite3())
+	a.NotError(err)
+
+	_, err = db.Exec("DROP TABLE IF EXISTS #page_users")
+	a.NotError(err)
+	_, err = db.Exec("CREATE TABLE #page_users({id} INTEGER PRIMARY KEY,{name} TEXT,{group} INTEGER)")
+	a.NotError(err)
+
+	for i := 1; i <= 25; i++ {
+		_, err = db.Exec("INSERT INTO #page_users({id},{name},{group}) VALUES(?,?,?)", i, "u"+strconv.Itoa(i), i%3)
+		a.NotError(err)
+	}
+
+	return db
+}
+
+func TestSelectStmt_Paginate(t *testing.T) {
+	a := assert.New(t)
+	db := initPageDB(a)
+	defer func() {
+		_, err := db.Exec("DROP TABLE #page_users")
+		a.NotError(err)
+		a.NotError(db.Close())
+		a.NotError(os.Remove("./test.db"))
+	}()
+
+	users := []*pageUser{}
+	p, err := db.SQL().Select().Select("*").From("#page_users").Desc("{id}").
+		Paginate(&users, 2, 10)
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Total, 25).Equal(p.Pages, 3).Equal(p.Count, 10).True(p.HasNext())
+	a.Equal(len(users), 10).Equal(users[0].ID, 15)
+
+	users = []*pageUser{}
+	p, err = db.SQL().Select().Select("*").From("#page_users").Asc("{id}").
+		Paginate(&users, 3, 10)
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Count, 5).False(p.HasNext())
+	a.Equal(len(users), 5).Equal(users[0].ID, 21)
+
+	// 超出范围
+	users = []*pageUser{}
+	p, err = db.SQL().Select().Select("*").From("#page_users").Paginate(&users, 4, 10)
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Count, 0).Equal(p.Total, 25).Empty(users)
+
+	// distinct
+	groups := []*pageUser{}
+	p, err = db.SQL().Select().Distinct().Select("{group}").From("#page_users").Asc("{group}").
+		Paginate(&groups, 1, 2)
+	a.NotError(err).NotNil(p)
+	a.Equal(p.Total, 3).Equal(p.Pages, 2).Equal(p.Count, 2)
+
+	_, err = db.SQL().Select().Select("*").From("#page_users").Paginate(&users, 0, 10)
+	a.Error(err)
+}
+
+func TestSelectStmt_Keyset(t *testing.T) {
+	a := assert.New(t)
+	db := initPageDB(a)
+	defer func() {
+		_, err := db.Exec("DROP TABLE #page_users")
+		a.NotError(err)
+		a.NotError(db.Close())
+		a.NotError(os.Remove("./test.db"))
+	}()
+
+	// 单列
+	var ids []int64
+	cursor := ""
+	for {
+		users := []*pageUser{}
+		next, err := db.SQL().Select().Select("*").From("#page_users").
+			Keyset(&users, 10, cursor, "id")
+		a.NotError(err)
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	a.Equal(len(ids), 25)
+	for i, id := range ids {
+		a.Equal(id, i+1)
+	}
+
+	// 重复使用同一个切片，最后一页不应该包含上一页遗留的数据
+	users := []*pageUser{}
+	next, err := db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 20, "", "id")
+	a.NotError(err).NotEmpty(next)
+	a.Equal(len(users), 20)
+	next, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 20, next, "id")
+	a.NotError(err).Empty(next)
+	a.Equal(len(users), 5).Equal(users[0].ID, 21).Equal(users[4].ID, 25)
+
+	// 多列
+	users = []*pageUser{}
+	next, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 8, "", "group", "id")
+	a.NotError(err).NotEmpty(next)
+	a.Equal(len(users), 8).Equal(users[7].Group, 0).Equal(users[7].ID, 24)
+
+	users = []*pageUser{}
+	next, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 8, next, "group", "id")
+	a.NotError(err).NotEmpty(next)
+	a.Equal(users[0].Group, 1).Equal(users[0].ID, 1)
+
+	// 无效的 cursor
+	users = []*pageUser{}
+	_, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 8, "invalid cursor", "id")
+	a.Equal(err, sqlbuilder.ErrInvalidCursor)
+
+	// 列数量与 cursor 不匹配
+	_, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(&users, 8, next, "id")
+	a.Equal(err, sqlbuilder.ErrInvalidCursor)
+
+	// 非切片指针
+	_, err = db.SQL().Select().Select("*").From("#page_users").
+		Keyset(users, 8, "", "id")
+	a.Error(err)
+}
@@ -21,7 +21,7 @@ type SelectStmt struct {
 	where     *WhereStmt
 	cols      []string
 	distinct  bool
-	forupdate bool
+	lock      *Lock
 
 	// COUNT 查询的列内容
 	countExpr string
@@ -66,7 +66,7 @@ func (stmt *SelectStmt) Reset() {
 	stmt.where.Reset()
 	stmt.cols = stmt.cols[:0]
 	stmt.distinct = false
-	stmt.forupdate = false
+	stmt.lock = nil
 
 	stmt.countExpr = ""
 
@@ -162,8 +162,12 @@ func (stmt *SelectStmt) SQL() (string, []interface{}, error) {
 	}
 
 	// for update
-	if stmt.forupdate {
-		buf.WriteString(" FOR UPDATE")
+	if stmt.lock != nil {
+		query, err := stmt.dialect.LockSQL(stmt.lock)
+		if err != nil {
+			return "", nil, err
+		}
+		buf.WriteString(query)
 	}
 
 	return buf.String(), args, nil
@@ -264,7 +268,13 @@ func (stmt *SelectStmt) orderBy(asc bool, col ...string) *SelectStmt {
 
 // ForUpdate 添加 FOR UPDATE 语句部分
 func (stmt *SelectStmt) ForUpdate() *SelectStmt {
-	stmt.forupdate = true
+	return stmt.Lock(&Lock{})
+}
+
+// Lock 指定锁定子句，比如 FOR SHARE、FOR UPDATE SKIP LOCKED 等，
+// 具体的语句由 Dialect.LockSQL() 生成，lock 为 nil 表示取消锁定。
+func (stmt *SelectStmt) Lock(lock *Lock) *SelectStmt {
+	stmt.lock = lock
 	return stmt
 }
 
@@ -331,6 +341,10 @@ func (stmt *SelectStmt) QueryFloat(colName string) (float64, error) {
 	}
 	defer rows.Close()
 
+	return queryFloat(rows, colName)
+}
+
+func queryFloat(rows *sql.Rows, colName string) (float64, error) {
 	cols, err := fetch.ColumnString(true, colName, rows)
 	if err != nil {
 		return 0, err
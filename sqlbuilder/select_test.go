@@ -69,4 +69,14 @@ func TestSelect_sqlite3(t *testing.T) {
 	query, args, err = s.SQL()
 	a.NotError(err).Empty(args)
 	sqltest.Equal(a, query, "select c1,c2 from #tb1")
+
+	// sqlite3 忽略普通的锁定语句
+	s.ForUpdate()
+	query, args, err = s.SQL()
+	a.NotError(err).Empty(args)
+	sqltest.Equal(a, query, "select c1,c2 from #tb1")
+
+	s.Lock(&sqlbuilder.Lock{Wait: sqlbuilder.LockSkipLocked})
+	_, _, err = s.SQL()
+	a.Equal(err, sqlbuilder.ErrLockNotSupported)
 }
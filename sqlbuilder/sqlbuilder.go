@@ -28,6 +28,9 @@ var (
 
 	// ErrArgsNotMatch 在生成的 SQL 语句中，传递的参数与语句的占位符数量不匹配。
 	ErrArgsNotMatch = errors.New("列与值的数量不匹配")
+
+	// ErrLockNotSupported 当前数据库不支持 Select 语句中指定的锁定方式
+	ErrLockNotSupported = errors.New("不支持该锁定方式")
 )
 
 // SQLBuilder 对 bytes.Buffer 的一个简单封装。
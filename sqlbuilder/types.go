@@ -67,6 +67,29 @@ type Dialect interface {
 	// append 表示在 sql 不为空的情况下，sql 与现有的插入语句的结合方式，
 	// 如果为 true 表示直接添加在插入语句之后，否则为一条新的语句。
 	LastInsertID(table, col string) (sql string, append bool)
+
+	// 生成 Select 语句中的锁定子句，比如 ` FOR UPDATE NOWAIT`。
+	//
+	// 返回的语句应该以空格开头，不支持行锁的数据库可以返回空字符串；
+	// 不支持 lock 指定的锁定方式时，应该返回 ErrLockNotSupported。
+	LockSQL(lock *Lock) (string, error)
+}
+
+// LockWait 表示锁定的行被占用时的处理方式
+type LockWait int8
+
+// LockWait 的各类值
+const (
+	LockWaitDefault LockWait = iota // 等待锁释放
+	LockNoWait                      // NOWAIT，立即返回错误
+	LockSkipLocked                  // SKIP LOCKED，跳过已经被锁定的行
+)
+
+// Lock 表示 Select 语句中的锁定子句
+type Lock struct {
+	Share bool     // 是否为共享锁，默认为排它锁，即 FOR UPDATE
+	Wait  LockWait // 锁被占用时的处理方式
+	Of    []string // 仅锁定这些表，为空表示所有表
 }
 
 func exec(e Engine, stmt SQLer) (sql.Result, error) {
@@ -101,3 +101,81 @@ func (stmt *WhereStmt) AndWhere(w *WhereStmt) *WhereStmt {
 func (stmt *WhereStmt) OrWhere(w *WhereStmt) *WhereStmt {
 	return stmt.addWhere(false, w)
 }
+
+// 生成 col IN(?,?) 或是 (col1,col2) IN((?,?),(?,?)) 形式的条件语句，
+// expand 为 true 时，多列生成 ((col1=? AND col2=?) OR (...)) 的形式。
+// vals 中的每一个元素表示一组值，其长度必须与 cols 相同；
+// vals 为空时，生成永远不成立的 1=0。
+func (stmt *WhereStmt) in(and, expand bool, cols []string, vals ...[]interface{}) *WhereStmt {
+	if len(vals) == 0 {
+		return stmt.where(and, "1=0")
+	}
+
+	buf := New("")
+	args := make([]interface{}, 0, len(cols)*len(vals))
+
+	if len(cols) == 1 {
+		buf.WriteString(cols[0]).WriteString(" IN(")
+		for _, v := range vals {
+			buf.WriteString("?,")
+			args = append(args, v...)
+		}
+		buf.TruncateLast(1).WriteByte(')')
+		return stmt.where(and, buf.String(), args...)
+	}
+
+	buf.WriteByte('(')
+	if expand {
+		for _, v := range vals {
+			buf.WriteByte('(')
+			for _, col := range cols {
+				buf.WriteString(col).WriteString("=? AND ")
+			}
+			buf.TruncateLast(len(" AND ")).WriteString(") OR ")
+			args = append(args, v...)
+		}
+		buf.TruncateLast(len(" OR ")).WriteByte(')')
+		return stmt.where(and, buf.String(), args...)
+	}
+
+	for _, col := range cols {
+		buf.WriteString(col).WriteByte(',')
+	}
+	buf.TruncateLast(1).WriteString(") IN(")
+	for _, v := range vals {
+		buf.WriteByte('(')
+		for range cols {
+			buf.WriteString("?,")
+		}
+		buf.TruncateLast(1).WriteString("),")
+		args = append(args, v...)
+	}
+	buf.TruncateLast(1).WriteByte(')')
+
+	return stmt.where(and, buf.String(), args...)
+}
+
+// AndIn 添加一条 AND ... IN(...) 语句
+//
+// cols 为多列时，生成 (col1,col2) IN((?,?),(?,?)) 形式的语句；
+// vals 中的每一个元素表示一组值，其长度必须与 cols 相同，否则在生成 SQL 时返回错误；
+// vals 为空时，生成永远不成立的 1=0 条件。
+func (stmt *WhereStmt) AndIn(cols []string, vals ...[]interface{}) *WhereStmt {
+	return stmt.in(true, false, cols, vals...)
+}
+
+// OrIn 添加一条 OR ... IN(...) 语句，具体参考 AndIn()。
+func (stmt *WhereStmt) OrIn(cols []string, vals ...[]interface{}) *WhereStmt {
+	return stmt.in(false, false, cols, vals...)
+}
+
+// AndInExpand 功能同 AndIn()，但多列时生成 ((col1=? AND col2=?) OR (col1=? AND col2=?)) 形式的语句，
+// 用于不支持 (col1,col2) IN(...) 的数据库，比如 sqlite3。
+func (stmt *WhereStmt) AndInExpand(cols []string, vals ...[]interface{}) *WhereStmt {
+	return stmt.in(true, true, cols, vals...)
+}
+
+// OrInExpand 添加一条 OR ... IN(...) 语句，具体参考 AndInExpand()。
+func (stmt *WhereStmt) OrInExpand(cols []string, vals ...[]interface{}) *WhereStmt {
+	return stmt.in(false, true, cols, vals...)
+}
@@ -69,3 +69,44 @@ func TestWhere_addWhere(t *testing.T) {
 	a.Equal(args, []interface{}{2, 3, 4, 4})
 	sqltest.Equal(a, query, "(id=? or id=? or(id=?)) or (id=?)")
 }
+
+func TestWhere_In(t *testing.T) {
+	a := assert.New(t)
+	w := Where()
+
+	w.And("id>?", 1).AndIn([]string{"id"}, []interface{}{2}, []interface{}{3})
+	query, args, err := w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, 2, 3})
+	sqltest.Equal(a, query, "id>? AND id IN(?,?)")
+
+	w.Reset()
+	w.AndIn([]string{"id", "name"}, []interface{}{1, "n1"}, []interface{}{2, "n2"}).
+		OrIn([]string{"id"}, []interface{}{5})
+	query, args, err = w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 2, "n2", 5})
+	sqltest.Equal(a, query, "(id,name) IN((?,?),(?,?)) OR id IN(?)")
+
+	w.Reset()
+	w.AndInExpand([]string{"id", "name"}, []interface{}{1, "n1"}, []interface{}{2, "n2"}).
+		OrInExpand([]string{"id"}, []interface{}{5})
+	query, args, err = w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1, "n1", 2, "n2", 5})
+	sqltest.Equal(a, query, "((id=? AND name=?) OR (id=? AND name=?)) OR id IN(?)")
+
+	// 值的数量与列不匹配
+	w.Reset()
+	w.AndIn([]string{"id", "name"}, []interface{}{1})
+	query, args, err = w.SQL()
+	a.Equal(err, ErrArgsNotMatch).Nil(args).Empty(query)
+
+	// 空值
+	w.Reset()
+	w.And("id>?", 1).AndIn([]string{"id"}).OrInExpand([]string{"id", "name"})
+	query, args, err = w.SQL()
+	a.NotError(err)
+	a.Equal(args, []interface{}{1})
+	sqltest.Equal(a, query, "id>? AND 1=0 OR 1=0")
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/issue9/orm/sqlbuilder"
+)
+
+// ErrTenantMismatch 对象中租户列的值与 Engine 所限定的租户不一致
+var ErrTenantMismatch = errors.New("租户不匹配")
+
+// WithTenant 返回一个限定于租户 tenant 的 DB 实例。
+//
+// 对于通过 tenant 属性指定了租户列的模型，由返回的实例执行的操作：
+//  - 查询、更新和删除时，会自动加上租户列等于 tenant 的条件；
+//  - 插入时，若租户列为零值，则会被设置为 tenant；
+//  - 租户列不为零值且与 tenant 不同，则返回 ErrTenantMismatch；
+//  - 更新时不会修改租户列，即数据不能在租户之间移动。
+// 未指定租户列的模型不受影响，直接执行的 SQL 语句也不受影响。
+//
+// 返回的实例与当前实例共用连接池，tenant 为 nil 表示取消限定。
+func (db *DB) WithTenant(tenant interface{}) *DB {
+	inst := *db
+	inst.tenant = tenant
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// WithTenant 返回一个限定于租户 tenant 的 Tx 实例。
+//
+// 返回的实例与当前实例属于同一个事务，具体可参考 DB.WithTenant()。
+func (tx *Tx) WithTenant(tenant interface{}) *Tx {
+	inst := *tx
+	inst.db = tx.db.WithTenant(tenant)
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// 获取 e 限定的租户值，并转换成 m.Tenant 对应的类型。
+//
+// 若模型没有租户列或是 e 未限定租户，则返回无效的 reflect.Value。
+func tenantValue(e Engine, m *Model) (reflect.Value, error) {
+	if m.Tenant == nil {
+		return reflect.Value{}, nil
+	}
+
+	db := dbOf(e)
+	if db == nil || db.tenant == nil {
+		return reflect.Value{}, nil
+	}
+	tenant := db.tenant
+
+	v := reflect.ValueOf(tenant)
+	typ := m.Tenant.GoType
+	if v.Type() == typ {
+		return v, nil
+	}
+
+	// 禁止数值到字符串的转换
+	if !v.Type().ConvertibleTo(typ) ||
+		(typ.Kind() == reflect.String && v.Kind() != reflect.String) {
+		return reflect.Value{}, fmt.Errorf("租户值的类型 %s 无法转换成 %s", v.Type(), typ)
+	}
+	return v.Convert(typ), nil
+}
+
+// 检测 rval 中租户列的值，若为零值且 set 为 true，则设置为 tv。
+func checkTenant(m *Model, rval reflect.Value, tv reflect.Value, set bool) error {
+	field := rval.FieldByName(m.Tenant.GoName)
+	if !field.IsValid() {
+		return fmt.Errorf("未找到该名称 %s 的值", m.Tenant.GoName)
+	}
+
+	if m.Tenant.IsZero(field) {
+		if !set {
+			return nil
+		}
+		if !field.CanSet() {
+			return fmt.Errorf("无法设置租户列 %s 的值", m.Tenant.GoName)
+		}
+		field.Set(tv)
+		return nil
+	}
+
+	if !reflect.DeepEqual(field.Interface(), tv.Interface()) {
+		return ErrTenantMismatch
+	}
+	return nil
+}
+
+// 为插入的对象设置租户列的值
+func applyTenant(e Engine, m *Model, rval reflect.Value) error {
+	tv, err := tenantValue(e, m)
+	if err != nil || !tv.IsValid() {
+		return err
+	}
+
+	return checkTenant(m, rval, tv, true)
+}
+
+// 为查询条件加上租户限定
+//
+// 返回值 scoped 表示是否加上了限定条件。
+func scopeWhere(e Engine, sb sqlbuilder.WhereStmter, m *Model, rval reflect.Value) (scoped bool, err error) {
+	tv, err := tenantValue(e, m)
+	if err != nil || !tv.IsValid() {
+		return false, err
+	}
+
+	if err = checkTenant(m, rval, tv, false); err != nil {
+		return false, err
+	}
+
+	sb.WhereStmt().And("{"+m.Tenant.Name+"}=?", tv.Interface())
+	return true, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+type multiTenant struct {
+	ID int64 `orm:"name(id);pk;tenant"`
+	T2 int64 `orm:"name(t2);tenant"`
+}
+
+func TestModel_tenant(t *testing.T) {
+	a := assert.New(t)
+
+	m, err := orm.NewModel(&modeltest.Order{})
+	a.NotError(err).NotNil(m)
+	a.Equal(m.Tenant, m.Cols["tenant_id"])
+
+	m, err = orm.NewModel(&multiTenant{})
+	a.Error(err).Nil(m)
+}
+
+func TestDB_WithTenant(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	a.NotError(db.Create(&modeltest.Order{}))
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Order{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+
+	t1 := db.WithTenant(1)
+	t2 := db.WithTenant(int64(2))
+
+	// insert 自动设置租户列
+	o := &modeltest.Order{ID: 1, Title: "o1"}
+	_, err := t1.Insert(o)
+	a.NotError(err).Equal(o.Tenant, 1)
+	_, err = t2.Insert(&modeltest.Order{ID: 2, Title: "o2"})
+	a.NotError(err)
+	_, err = t1.Insert(&modeltest.Order{ID: 3, Tenant: 2, Title: "o3"})
+	a.Equal(err, orm.ErrTenantMismatch)
+
+	// 无法查询到其它租户的数据
+	o = &modeltest.Order{ID: 2}
+	a.Equal(t1.Select(o), orm.ErrNoRows)
+	a.Empty(o.Title)
+	o = &modeltest.Order{ID: 2}
+	a.NotError(t2.Select(o))
+	a.Equal(o.Title, "o2").Equal(o.Tenant, 2)
+
+	// count 不需要其它条件
+	cnt, err := t1.Count(&modeltest.Order{})
+	a.NotError(err).Equal(cnt, 1)
+	_, err = db.Count(&modeltest.Order{})
+	a.Error(err)
+
+	// update
+	r, err := t1.Update(&modeltest.Order{ID: 2, Title: "changed"})
+	a.NotError(err)
+	n, err := r.RowsAffected()
+	a.NotError(err).Equal(n, 0)
+	_, err = t1.Update(&modeltest.Order{ID: 1, Tenant: 2, Title: "move"})
+	a.Equal(err, orm.ErrTenantMismatch)
+
+	// delete
+	r, err = t1.Delete(&modeltest.Order{ID: 2})
+	a.NotError(err)
+	n, err = r.RowsAffected()
+	a.NotError(err).Equal(n, 0)
+
+	// 事务
+	tx, err := db.Begin()
+	a.NotError(err)
+	tx1 := tx.WithTenant(1)
+	a.NotError(tx1.InsertMany([]*modeltest.Order{
+		&modeltest.Order{ID: 11, Title: "o11"},
+		&modeltest.Order{ID: 12, Title: "o12"},
+	}))
+	count, err := tx1.UpdateMany([]*modeltest.Order{
+		&modeltest.Order{ID: 11, Title: "u11"},
+		&modeltest.Order{ID: 2, Title: "u2"}, // 其它租户
+	})
+	a.NotError(err).Equal(count, 1)
+	_, err = tx1.UpdateMany([]*modeltest.Order{&modeltest.Order{ID: 11}}, "tenant_id")
+	a.Error(err)
+	count, err = tx1.DeleteMany([]*modeltest.Order{
+		&modeltest.Order{ID: 12},
+		&modeltest.Order{ID: 2}, // 其它租户
+	})
+	a.NotError(err).Equal(count, 1)
+	a.NotError(tx.Commit())
+
+	o = &modeltest.Order{ID: 11}
+	a.NotError(db.Select(o))
+	a.Equal(o.Title, "u11").Equal(o.Tenant, 1)
+	o = &modeltest.Order{ID: 2}
+	a.NotError(db.Select(o))
+	a.Equal(o.Title, "o2")
+	hasCount(db, a, "orders", 3)
+
+	// 类型不匹配
+	_, err = db.WithTenant("1").Insert(&modeltest.Order{ID: 20})
+	a.Error(err)
+}
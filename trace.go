@@ -0,0 +1,152 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Span 中使用的属性名称，与 OpenTelemetry 的语义约定相同。
+const (
+	AttrStatement    = "db.statement"     // 执行的 SQL 语句，已经替换了表名前缀等内容
+	AttrTable        = "db.sql.table"     // 表名，即 Model.Name
+	AttrOperation    = "db.operation"     // 操作类型，比如 insert、select 等
+	AttrRowsAffected = "db.rows_affected" // 受影响的行数
+)
+
+// Tracer 用于创建追踪的 span
+//
+// 只定义了最基本的功能，可以很方便地包装 OpenTelemetry 等追踪系统，
+// 而不需要引入额外的依赖。
+type Tracer interface {
+	// 创建一个名为 name 的 span，若 ctx 中已经包含了 span，则作为其父节点。
+	//
+	// 返回的 context 中应该包含新建的 span。
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 表示追踪中的一个操作
+type Span interface {
+	// 设置属性，key 可以是 AttrStatement 等值。
+	SetAttribute(key string, value interface{})
+
+	// 记录操作返回的错误
+	RecordError(err error)
+
+	// 结束当前操作
+	End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+// WithTracer 返回一个通过 t 创建 span 的 DB 实例。
+//
+// 由返回的实例执行的每条语句都会创建一个 span，
+// Insert、Select 等以模型为参数的操作也会创建一个包含表名的 span，
+// 其中执行的语句作为其子节点；事务则会创建一个从开始到提交或回滚的 span。
+//
+// 以 Context 结尾的方法从参数 ctx 中获取父节点，其它方法则没有父节点。
+// 返回的实例与当前实例共用连接池，t 为 nil 表示不追踪。
+// 也可以通过 Options.Tracer 在初始化时指定。
+func (db *DB) WithTracer(t Tracer) *DB {
+	inst := *db
+	inst.tracer = t
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// 当前实例执行语句时默认的 context
+func (db *DB) context() context.Context {
+	if db.ctx != nil {
+		return db.ctx
+	}
+	return context.Background()
+}
+
+func (db *DB) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if db.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return db.tracer.Start(ctx, name)
+}
+
+// 为执行语句 query 创建 span
+func (db *DB) startStmtSpan(ctx context.Context, name, query string) (context.Context, Span) {
+	ctx, span := db.startSpan(ctx, name)
+	span.SetAttribute(AttrStatement, query)
+	return ctx, span
+}
+
+// 为模型 m 的 op 操作创建 span
+//
+// 返回的 Engine 与 e 的类型相同，由其执行的语句都将作为该 span 的子节点。
+func traceModel(e Engine, op string, m *Model) (Engine, Span) {
+	var db *DB
+	switch v := e.(type) {
+	case *DB:
+		db = v
+	case *Tx:
+		db = v.db
+	}
+	if db == nil || db.tracer == nil {
+		return e, noopSpan{}
+	}
+
+	var ctx context.Context
+	if tx, ok := e.(*Tx); ok {
+		ctx = tx.context()
+	} else {
+		ctx = db.context()
+	}
+
+	ctx, span := db.tracer.Start(ctx, "orm."+op)
+	span.SetAttribute(AttrOperation, op)
+	span.SetAttribute(AttrTable, m.Name)
+
+	if tx, ok := e.(*Tx); ok {
+		inst := *tx
+		inst.ctx = ctx
+		inst.sql = &SQL{engine: &inst}
+		return &inst, span
+	}
+
+	inst := *db
+	inst.ctx = ctx
+	inst.sql = &SQL{engine: &inst}
+	return &inst, span
+}
+
+// 结束 span，rows 为受影响的行数，小于 0 表示未知。
+func endSpan(span Span, rows int64, err error) {
+	if rows >= 0 {
+		span.SetAttribute(AttrRowsAffected, rows)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// 获取 r 中受影响的行数，无法获取时返回 -1。
+func rowsAffected(r sql.Result) int64 {
+	if r == nil {
+		return -1
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
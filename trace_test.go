@@ -0,0 +1,114 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	name   string
+	parent *testSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+// 记录所有 span 的 Tracer 实现
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, orm.Span) {
+	s := &testSpan{name: name, attrs: map[string]interface{}{}}
+	if p, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		s.parent = p
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *testSpan) SetAttribute(key string, val interface{}) { s.attrs[key] = val }
+
+func (s *testSpan) RecordError(err error) { s.err = err }
+
+func (s *testSpan) End() { s.ended = true }
+
+// 查找第一个名为 name 的 span
+func (t *testTracer) find(name string) *testSpan {
+	for _, s := range t.spans {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestDB_WithTracer(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	tracer := &testTracer{}
+	tdb := db.WithTracer(tracer)
+
+	// 模型操作
+	_, err := tdb.Insert(&modeltest.UserInfo{UID: 10, FirstName: "f10", LastName: "l10"})
+	a.NotError(err)
+	ins := tracer.find("orm.insert")
+	a.NotNil(ins).True(ins.ended).Nil(ins.parent)
+	a.Equal(ins.attrs[orm.AttrTable], "user_info").Equal(ins.attrs[orm.AttrRowsAffected], 1)
+	exec := tracer.find("orm.Exec")
+	a.NotNil(exec).Equal(exec.parent, ins).True(exec.ended)
+	a.Contains(exec.attrs[orm.AttrStatement], "prefix_user_info")
+	a.Equal(exec.attrs[orm.AttrRowsAffected], 1)
+
+	// 错误
+	a.Equal(tdb.Select(&modeltest.UserInfo{UID: 100}), orm.ErrNoRows)
+	sel := tracer.find("orm.select")
+	a.NotNil(sel).Equal(sel.err, orm.ErrNoRows)
+	query := tracer.find("orm.Query")
+	a.NotNil(query).Equal(query.parent, sel).Nil(query.err)
+
+	// 事务
+	tracer.spans = tracer.spans[:0]
+	ctx, root := tracer.Start(context.Background(), "root")
+	tx, err := tdb.BeginTx(ctx, nil)
+	a.NotError(err)
+	_, err = tx.Update(&modeltest.UserInfo{UID: 10, FirstName: "f11"})
+	a.NotError(err)
+	_, err = tx.Exec("DELETE FROM #user_info WHERE {uid}=?", 10)
+	a.NotError(err)
+	a.NotError(tx.Commit())
+	a.Error(tx.Rollback())
+
+	txSpan := tracer.find("orm.Tx")
+	a.NotNil(txSpan).True(txSpan.ended).Equal(txSpan.parent, root)
+	a.Equal(txSpan.attrs[orm.AttrOperation], "commit")
+	upd := tracer.find("orm.update")
+	a.NotNil(upd).Equal(upd.parent, txSpan)
+	a.Equal(tracer.find("orm.Exec").parent, upd)
+	a.Equal(tracer.spans[len(tracer.spans)-1].parent, txSpan) // DELETE 语句
+
+	// 未指定 Tracer
+	tracer.spans = tracer.spans[:0]
+	_, err = db.Insert(&modeltest.UserInfo{UID: 11, FirstName: "f11", LastName: "l11"})
+	a.NotError(err).Empty(tracer.spans)
+}
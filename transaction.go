@@ -0,0 +1,83 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryPolicy 事务的重试策略
+type RetryPolicy struct {
+	// 最大的重试次数，不包含第一次执行。
+	MaxRetries int
+
+	// 返回第 attempt 次重试之前需要等待的时间，attempt 从 1 开始。
+	//
+	// 为空表示不等待，可以使用 ExponentialBackoff() 生成。
+	Backoff func(attempt int) time.Duration
+}
+
+// ExponentialBackoff 返回一个指数增长的等待时间函数
+//
+// 第 n 次重试等待 base*2^(n-1)，但最多不超过 max。
+func ExponentialBackoff(base, max time.Duration) func(int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// Transaction 在事务中执行 f
+//
+// f 返回错误或是发生 panic 时回滚事务，否则提交事务。
+// 回滚失败时，返回的错误依然包含 f 返回的错误，可以通过 errors.Is() 等判断。
+func (db *DB) Transaction(f func(tx *Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if msg := recover(); msg != nil {
+			tx.Rollback()
+			panic(msg)
+		}
+	}()
+
+	if err = f(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w，且回滚事务时发生错误: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TransactionRetry 在事务中执行 f，遇到临时性错误时按 p 重新执行整个事务。
+//
+// 是否为临时性错误由 Dialect.Retryable() 判断，比如死锁和序列化失败等，
+// 其它错误会直接返回。f 可能会被执行多次，所以不应该包含事务之外的副作用。
+// p 为空时，等同于 Transaction()。
+func (db *DB) TransactionRetry(p *RetryPolicy, f func(tx *Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		err := db.Transaction(f)
+		if err == nil || p == nil || attempt >= p.MaxRetries || !db.Dialect().Retryable(err) {
+			return err
+		}
+
+		if p.Backoff != nil {
+			time.Sleep(p.Backoff(attempt + 1))
+		}
+	}
+}
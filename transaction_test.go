@@ -0,0 +1,143 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	a := assert.New(t)
+
+	f := orm.ExponentialBackoff(time.Millisecond, 5*time.Millisecond)
+	a.Equal(f(1), time.Millisecond)
+	a.Equal(f(2), 2*time.Millisecond)
+	a.Equal(f(3), 4*time.Millisecond)
+	a.Equal(f(4), 5*time.Millisecond)
+	a.Equal(f(100), 5*time.Millisecond)
+}
+
+func TestDB_Transaction(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a) // 包含一条 groups 记录
+	defer clearData(db, a)
+
+	// 提交
+	a.NotError(db.Transaction(func(tx *orm.Tx) error {
+		_, err := tx.Insert(&modeltest.Group{Name: "g1"})
+		return err
+	}))
+	hasCount(db, a, "groups", 2)
+
+	// 回滚
+	errRollback := errors.New("rollback")
+	err := db.Transaction(func(tx *orm.Tx) error {
+		if _, err := tx.Insert(&modeltest.Group{Name: "g2"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	a.Equal(err, errRollback)
+	hasCount(db, a, "groups", 2)
+
+	// 回滚失败时，依然返回 f 的错误
+	err = db.Transaction(func(tx *orm.Tx) error {
+		a.NotError(tx.Rollback())
+		return errRollback
+	})
+	a.True(errors.Is(err, errRollback), err)
+
+	// panic 时回滚事务
+	func() {
+		defer func() {
+			a.Equal(recover(), "panic")
+		}()
+
+		db.Transaction(func(tx *orm.Tx) error {
+			_, err := tx.Insert(&modeltest.Group{Name: "g3"})
+			a.NotError(err)
+			panic("panic")
+		})
+	}()
+	hasCount(db, a, "groups", 2)
+	_, err = db.Insert(&modeltest.Group{Name: "g4"}) // 事务已经释放
+	a.NotError(err)
+	hasCount(db, a, "groups", 3)
+}
+
+func TestDB_TransactionRetry(t *testing.T) {
+	if driver != "sqlite3" { // 模拟的错误信息仅对 sqlite3 有效
+		return
+	}
+
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a) // 包含一条 groups 记录
+	defer clearData(db, a)
+
+	errLocked := errors.New("database is locked")
+	policy := &orm.RetryPolicy{
+		MaxRetries: 3,
+		Backoff:    orm.ExponentialBackoff(time.Millisecond, 2*time.Millisecond),
+	}
+
+	// 第三次成功
+	attempts := 0
+	a.NotError(db.TransactionRetry(policy, func(tx *orm.Tx) error {
+		attempts++
+		if _, err := tx.Insert(&modeltest.Group{Name: "g"}); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errLocked
+		}
+		return nil
+	}))
+	a.Equal(attempts, 3)
+	hasCount(db, a, "groups", 2)
+
+	// 超过重试次数
+	attempts = 0
+	err := db.TransactionRetry(policy, func(tx *orm.Tx) error {
+		attempts++
+		return errLocked
+	})
+	a.Equal(err, errLocked).Equal(attempts, 4)
+
+	// 回滚失败不影响重试的判断
+	attempts = 0
+	err = db.TransactionRetry(policy, func(tx *orm.Tx) error {
+		attempts++
+		a.NotError(tx.Rollback())
+		return errLocked
+	})
+	a.True(errors.Is(err, errLocked), err).Equal(attempts, 4)
+
+	// 不可重试的错误
+	attempts = 0
+	errOther := errors.New("other")
+	err = db.TransactionRetry(policy, func(tx *orm.Tx) error {
+		attempts++
+		return errOther
+	})
+	a.Equal(err, errOther).Equal(attempts, 1)
+
+	// 未指定策略
+	attempts = 0
+	err = db.TransactionRetry(nil, func(tx *orm.Tx) error {
+		attempts++
+		return errLocked
+	})
+	a.Equal(err, errLocked).Equal(attempts, 1)
+}
@@ -7,9 +7,12 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"reflect"
+	"time"
 
 	"github.com/issue9/orm/fetch"
+	"github.com/issue9/orm/sqlbuilder"
 )
 
 // Tx 事务对象
@@ -17,24 +20,88 @@ type Tx struct {
 	db    *DB
 	stdTx *sql.Tx
 	sql   *SQL
+
+	ctx  context.Context // 包含 span 的 context，不带 context 参数的方法使用此值
+	span Span            // 整个事务的 span
 }
 
 // Begin 开始一个新的事务
 func (db *DB) Begin() (*Tx, error) {
-	tx, err := db.stdDB.Begin()
+	return db.BeginTx(db.context(), nil)
+}
+
+// BeginTx 开始一个新的事务
+//
+// ctx 和 opts 的作用与 sql.DB.BeginTx() 相同，
+// 若指定了 Tracer，会以 ctx 中的 span 为父节点创建整个事务的 span。
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	ctx, span := db.startSpan(ctx, "orm.Tx")
+	tx, err := db.stdDB.BeginTx(ctx, opts)
 	if err != nil {
+		endSpan(span, -1, err)
 		return nil, err
 	}
 
+	if db.collector != nil {
+		db.collector.TxBegin()
+	}
+
 	inst := &Tx{
 		db:    db,
 		stdTx: tx,
+		ctx:   ctx,
+		span:  span,
 	}
 	inst.sql = &SQL{engine: inst}
 
 	return inst, nil
 }
 
+// 当前事务执行语句时默认的 context
+func (tx *Tx) context() context.Context {
+	if tx.ctx != nil {
+		return tx.ctx
+	}
+	return context.Background()
+}
+
+// WithPrefix 返回一个使用表名前缀 tablePrefix 的 Tx 实例。
+//
+// 返回的实例与当前实例属于同一个事务，在任意一个实例上提交或回滚，
+// 都将作用于整个事务。
+func (tx *Tx) WithPrefix(tablePrefix string) *Tx {
+	inst := *tx
+	inst.db = tx.db.WithPrefix(tablePrefix)
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// IgnoreNotFound 返回一个不返回 ErrNoRows 的 Tx 实例，具体可参考 DB.IgnoreNotFound()。
+//
+// 返回的实例与当前实例属于同一个事务。
+func (tx *Tx) IgnoreNotFound() *Tx {
+	inst := *tx
+	inst.db = tx.db.IgnoreNotFound()
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// CheckAffected 返回一个在 Delete 未删除任何数据时返回 ErrNoRows 的 Tx 实例，
+// 具体可参考 DB.CheckAffected()。
+//
+// 返回的实例与当前实例属于同一个事务。
+func (tx *Tx) CheckAffected() *Tx {
+	inst := *tx
+	inst.db = tx.db.CheckAffected()
+	inst.sql = &SQL{engine: &inst}
+	return &inst
+}
+
+// TablePrefix 返回表名前缀
+func (tx *Tx) TablePrefix() string {
+	return tx.db.tablePrefix
+}
+
 // StdTx 返回标准库的 *sql.Tx 对象。
 func (tx *Tx) StdTx() *sql.Tx {
 	return tx.stdTx
@@ -42,89 +109,87 @@ func (tx *Tx) StdTx() *sql.Tx {
 
 // Query 执行一条查询语句。
 func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx.stdTx.Query(query, args...)
+	return tx.QueryContext(tx.context(), query, args...)
 }
 
 // QueryContext 执行一条查询语句。
 func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
+	query, args, err := tx.db.prepareSQL(query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return tx.stdTx.QueryContext(ctx, query, args...)
+	ctx, span := tx.db.startStmtSpan(ctx, "orm.Query", query)
+	start := time.Now()
+	rows, err := tx.stdTx.QueryContext(ctx, query, args...)
+	err = tx.db.finish(query, start, err)
+	endSpan(span, -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
 }
 
 // QueryRow 执行一条查询语句。
 //
 // 如果生成语句出错，则会 panic
 func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		panic(err)
-	}
-
-	return tx.stdTx.QueryRow(query, args...)
+	return tx.QueryRowContext(tx.context(), query, args...)
 }
 
 // QueryRowContext 执行一条查询语句。
 //
-// 如果生成语句出错，则会 panic
+// 如果生成语句出错，则会 panic；
+// 执行过程中的错误由 sql.Row.Scan() 返回，不会经过 Dialect.TranslateError() 转换。
 func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
+	query, args, err := tx.db.prepareSQL(query, args)
 	if err != nil {
 		panic(err)
 	}
 
-	return tx.stdTx.QueryRowContext(ctx, query, args...)
+	ctx, span := tx.db.startStmtSpan(ctx, "orm.QueryRow", query)
+	start := time.Now()
+	row := tx.stdTx.QueryRowContext(ctx, query, args...)
+	tx.db.finish(query, start, nil)
+	endSpan(span, -1, nil)
+	return row
 }
 
 // Exec 执行一条 SQL 语句。
 func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
-	if err != nil {
-		return nil, err
-	}
-
-	return tx.stdTx.Exec(query, args...)
+	return tx.ExecContext(tx.context(), query, args...)
 }
 
 // ExecContext 执行一条 SQL 语句。
 func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
+	query, args, err := tx.db.prepareSQL(query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return tx.stdTx.ExecContext(ctx, query, args...)
-}
-
-// Prepare 将一条 SQL 语句进行预编译。
-func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
+	ctx, span := tx.db.startStmtSpan(ctx, "orm.Exec", query)
+	start := time.Now()
+	r, err := tx.stdTx.ExecContext(ctx, query, args...)
+	err = tx.db.finish(query, start, err)
+	endSpan(span, rowsAffected(r), err)
 	if err != nil {
 		return nil, err
 	}
+	return r, nil
+}
 
-	return tx.stdTx.Prepare(query)
+// Prepare 将一条 SQL 语句进行预编译。
+//
+// 预编译的语句不会对命名参数作转换。
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.PrepareContext(tx.context(), query)
 }
 
 // PrepareContext 将一条 SQL 语句进行预编译。
+//
+// 预编译的语句不会对命名参数作转换。
 func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
-	query = tx.db.replacer.Replace(query)
-	query, err := tx.db.dialect.SQL(query)
+	query, _, err := tx.db.prepareSQL(query, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -141,14 +206,41 @@ func (tx *Tx) Dialect() Dialect {
 //
 // 提交之后，整个 Tx 对象将不再有效。
 func (tx *Tx) Commit() error {
-	return tx.stdTx.Commit()
+	err := tx.stdTx.Commit()
+	tx.end(err, true)
+	if err != nil {
+		return tx.Dialect().TranslateError(err)
+	}
+	return nil
 }
 
 // Rollback 回滚事务。
 //
 // 回滚之后，整个 Tx 对象将不再有效。
 func (tx *Tx) Rollback() error {
-	return tx.stdTx.Rollback()
+	err := tx.stdTx.Rollback()
+	tx.end(err, false)
+	return err
+}
+
+// 通知 Collector 和 Tracer 事务已经结束，重复提交或回滚的操作会被忽略。
+func (tx *Tx) end(err error, commit bool) {
+	if err == sql.ErrTxDone {
+		return
+	}
+
+	if tx.db.collector != nil {
+		tx.db.collector.TxEnd(commit && err == nil)
+	}
+
+	if tx.span != nil {
+		if commit {
+			tx.span.SetAttribute(AttrOperation, "commit")
+		} else {
+			tx.span.SetAttribute(AttrOperation, "rollback")
+		}
+		endSpan(tx.span, -1, err)
+	}
 }
 
 // LastInsertID 插入数据，并获取其自增的 ID。
@@ -167,8 +259,11 @@ func (tx *Tx) Select(v interface{}) error {
 }
 
 // ForUpdate 读数据并锁定
-func (tx *Tx) ForUpdate(v interface{}) error {
-	return forUpdate(tx, v)
+//
+// lock 用于指定锁定方式，比如共享锁或是 SKIP LOCKED 等，
+// 不指定则为 FOR UPDATE，只有第一个参数有效。
+func (tx *Tx) ForUpdate(v interface{}, lock ...*sqlbuilder.Lock) error {
+	return forUpdate(tx, v, lock...)
 }
 
 // InsertMany 插入多条相同的数据。若需要向某张表中插入多条记录，
@@ -193,18 +288,114 @@ func (tx *Tx) InsertMany(v interface{}) error {
 		_, err := tx.Insert(v)
 		return err
 	case reflect.Array, reflect.Slice:
-		sql, err := buildInsertManySQL(tx, rval)
+		stmts, _, err := buildInsertManySQL(tx, rval)
 		if err != nil {
 			return err
 		}
 
-		_, err = sql.Exec()
-		return err
+		for _, stmt := range stmts {
+			if _, err = stmt.Exec(); err != nil {
+				return err
+			}
+		}
+		return nil
 	default:
 		return fetch.ErrInvalidKind
 	}
 }
 
+// LastInsertIDs 插入多条相同类型的数据，并按顺序返回其自增 ID。
+//
+// 若数据库支持在插入语句中直接返回自增列的值(比如 postgresql 的 RETURNING)，
+// 则会与 InsertMany() 一样分批插入，否则只能逐条插入以获取 ID。
+//
+// NOTE: 要求 v 的元素有定义自增列。
+func (tx *Tx) LastInsertIDs(v interface{}) ([]int64, error) {
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		rval = rval.Elem()
+	}
+
+	switch rval.Kind() {
+	case reflect.Struct: // 单个元素
+		id, err := tx.LastInsertID(v)
+		if err != nil {
+			return nil, err
+		}
+		return []int64{id}, nil
+	case reflect.Array, reflect.Slice:
+		if rval.Len() == 0 {
+			return nil, nil
+		}
+
+		m, _, err := getModel(rval.Index(0).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if m.AI == nil {
+			return nil, errors.New("该对象并没有自增列")
+		}
+
+		returning, appendable := tx.Dialect().LastInsertID("{#"+m.Name+"}", m.AI.Name)
+		if returning == "" || !appendable { // 无法批量获取 ID，只能逐条插入
+			ids := make([]int64, 0, rval.Len())
+			for i := 0; i < rval.Len(); i++ {
+				id, err := tx.LastInsertID(rval.Index(i).Interface())
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			return ids, nil
+		}
+
+		stmts, _, err := buildInsertManySQL(tx, rval)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]int64, 0, rval.Len())
+		for _, stmt := range stmts {
+			query, args, err := stmt.SQL()
+			if err != nil {
+				return nil, err
+			}
+
+			rows, err := tx.Query(query+" "+returning, args...)
+			if err != nil {
+				return nil, err
+			}
+
+			for rows.Next() {
+				var id int64
+				if err = rows.Scan(&id); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			if err = rows.Err(); err != nil {
+				rows.Close()
+				return nil, tx.Dialect().TranslateError(err)
+			}
+			rows.Close()
+		}
+		return ids, nil
+	default:
+		return nil, fetch.ErrInvalidKind
+	}
+}
+
+// BulkLoad 大批量导入数据，返回实际导入的数量。
+//
+// v 可以是由对象组成的数组，或是实现了 BulkIterator 接口的实例。
+// 若 Dialect 实现了 BulkLoader 接口，则使用数据库特有的导入方式，
+// 否则分批执行预编译的多行插入语句。列的信息由 NewModel() 获取，
+// 除自增列之外的所有列都会被导入，即使其值为零值。
+func (tx *Tx) BulkLoad(v interface{}) (int64, error) {
+	return bulkLoad(tx, v)
+}
+
 // Update 更新一条类型。
 func (tx *Tx) Update(v interface{}, cols ...string) (sql.Result, error) {
 	return update(tx, v, cols...)
@@ -215,6 +406,25 @@ func (tx *Tx) Delete(v interface{}) (sql.Result, error) {
 	return del(tx, v)
 }
 
+// UpdateMany 以尽量少的语句同时更新多条相同类型的数据，返回受影响的记录数量。
+//
+// 与 MultUpdate() 逐条更新不同，UpdateMany() 会根据 Dialect.UpdateManySQL()
+// 生成一条语句更新所有的数据，数据量过大时，会根据 Dialect.MaxArgs() 拆分成多条语句。
+//
+// 以主键作为更新的依据，cols 为需要更新的列，为空表示除主键之外的所有列；
+// 与 Update() 不同，零值也会被更新，乐观锁也不会起作用。
+func (tx *Tx) UpdateMany(v interface{}, cols ...string) (int64, error) {
+	return updateMany(tx, v, cols...)
+}
+
+// DeleteMany 以 DELETE ... WHERE pk IN(...) 的形式同时删除多条相同类型的数据，
+// 返回受影响的记录数量。
+//
+// 以主键作为删除的依据，数据量过大时，会根据 Dialect.MaxArgs() 拆分成多条语句。
+func (tx *Tx) DeleteMany(v interface{}) (int64, error) {
+	return deleteMany(tx, v)
+}
+
 // Count 查询符合 v 条件的记录数量。
 // v 中的所有非零字段都将参与查询。
 func (tx *Tx) Count(v interface{}) (int64, error) {
@@ -242,6 +452,10 @@ func (tx *Tx) Truncate(v interface{}) error {
 		return err
 	}
 
+	return tx.truncate(m)
+}
+
+func (tx *Tx) truncate(m *Model) error {
 	sqls := tx.Dialect().TruncateTableSQL(m)
 	for _, sql := range sqls {
 		if _, err := tx.Exec(sql); err != nil {
@@ -268,12 +482,22 @@ func (tx *Tx) MultInsert(objs ...interface{}) error {
 }
 
 // MultSelect 选择符合要求的一条或是多条记录。
+//
+// 若部分对象未找到对应的记录，会在查询完所有对象之后返回 *NotFoundError，
+// 其中包含了这些对象的索引。
 func (tx *Tx) MultSelect(objs ...interface{}) error {
-	for _, v := range objs {
-		if err := tx.Select(v); err != nil {
+	var indexes []int
+	for i, v := range objs {
+		if err := tx.Select(v); err == ErrNoRows {
+			indexes = append(indexes, i)
+		} else if err != nil {
 			return err
 		}
 	}
+
+	if len(indexes) > 0 {
+		return &NotFoundError{Indexes: indexes}
+	}
 	return nil
 }
 
@@ -298,34 +522,36 @@ func (tx *Tx) MultDelete(objs ...interface{}) error {
 }
 
 // MultCreate 创建数据表。
+//
+// 表按外键的依赖关系创建，被引用的表先于引用它的表创建。
+// 外键存在循环引用时，会先创建不包含这些外键的表，
+// 再通过 ALTER TABLE 添加外键。
 func (tx *Tx) MultCreate(objs ...interface{}) error {
 	if !tx.Dialect().TransactionalDDL() {
 		return tx.db.MultCreate(objs...)
 	}
 
-	for _, v := range objs {
-		if err := tx.Create(v); err != nil {
-			return err
-		}
-	}
-	return nil
+	return multCreate(tx, objs)
 }
 
 // MultDrop 删除表结构及数据。
+//
+// 按与 MultCreate 相反的顺序删除。
 func (tx *Tx) MultDrop(objs ...interface{}) error {
-	for _, v := range objs {
-		if err := tx.Drop(v); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return multDrop(tx, objs)
 }
 
 // MultTruncate 清除表内容，重置 ai，但保留表结构。
+//
+// 按与 MultCreate 相反的顺序清除。
 func (tx *Tx) MultTruncate(objs ...interface{}) error {
-	for _, v := range objs {
-		if err := tx.Truncate(v); err != nil {
+	ms, err := reverseModels(objs)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if err := tx.truncate(m); err != nil {
 			return err
 		}
 	}
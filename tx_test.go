@@ -5,10 +5,13 @@
 package orm_test
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/issue9/assert"
+	"github.com/issue9/orm"
 	"github.com/issue9/orm/internal/modeltest"
+	"github.com/issue9/orm/sqlbuilder"
 )
 
 func TestTx_InsertMany(t *testing.T) {
@@ -248,3 +251,195 @@ func TestTX(t *testing.T) {
 	a.NotError(tx.Commit())
 	hasCount(db, a, "users", 3)
 }
+
+func TestTx_ForUpdate(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	tx, err := db.Begin()
+	a.NotError(err)
+
+	u := &modeltest.UserInfo{UID: 1}
+	a.NotError(tx.ForUpdate(u))
+	a.Equal(u.FirstName, "f1")
+
+	u = &modeltest.UserInfo{UID: 2}
+	a.NotError(tx.ForUpdate(u, &sqlbuilder.Lock{Share: true}))
+	a.Equal(u.FirstName, "f2")
+
+	a.Equal(tx.ForUpdate(&modeltest.UserInfo{UID: 100}), orm.ErrNoRows)
+
+	// sqlite3 不支持 SKIP LOCKED
+	if driver == "sqlite3" {
+		err = tx.ForUpdate(&modeltest.UserInfo{UID: 1}, &sqlbuilder.Lock{Wait: sqlbuilder.LockSkipLocked})
+		a.Equal(err, sqlbuilder.ErrLockNotSupported)
+	}
+
+	a.NotError(tx.Commit())
+}
+
+func TestTx_InsertMany_batch(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer clearData(db, a)
+	a.NotError(db.Create(&modeltest.UserInfo{}))
+
+	// 超出 sqlite3 单条语句 999 个参数的限制，会被拆分成多条语句
+	us := make([]*modeltest.UserInfo, 0, 1000)
+	for i := 1; i <= 1000; i++ {
+		us = append(us, &modeltest.UserInfo{
+			UID:       i,
+			FirstName: "f" + strconv.Itoa(i),
+			LastName:  "l" + strconv.Itoa(i),
+			Sex:       "male",
+		})
+	}
+	a.NotError(db.InsertMany(us))
+	hasCount(db, a, "user_info", 1000)
+
+	u := &modeltest.UserInfo{UID: 1000}
+	a.NotError(db.Select(u))
+	a.Equal(u.FirstName, "f1000")
+
+	// 空数组
+	a.Error(db.InsertMany([]*modeltest.UserInfo{}))
+}
+
+func TestTx_LastInsertIDs(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&modeltest.Group{}))
+		clearData(db, a)
+	}()
+	a.NotError(db.Create(&modeltest.Group{}))
+
+	gs := make([]*modeltest.Group, 0, 300)
+	for i := 0; i < 300; i++ {
+		gs = append(gs, &modeltest.Group{Name: "g" + strconv.Itoa(i)})
+	}
+	ids, err := db.LastInsertIDs(gs)
+	a.NotError(err).Equal(len(ids), 300)
+	for i, id := range ids {
+		a.Equal(id, i+1)
+	}
+	hasCount(db, a, "groups", 300)
+
+	// 没有自增列
+	_, err = db.LastInsertIDs([]*modeltest.UserInfo{&modeltest.UserInfo{UID: 1}})
+	a.Error(err)
+}
+
+func TestTx_UpdateMany_DeleteMany(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	initData(db, a)
+	defer clearData(db, a)
+
+	tx, err := db.Begin()
+	a.NotError(err)
+
+	// 零值也会被更新
+	count, err := tx.UpdateMany([]*modeltest.UserInfo{
+		&modeltest.UserInfo{UID: 1, FirstName: "uf1", LastName: "ul1", Sex: "male"},
+		&modeltest.UserInfo{UID: 2, FirstName: "uf2", LastName: "ul2"},
+	})
+	a.NotError(err).Equal(count, 2)
+
+	// 指定列
+	count, err = tx.UpdateMany([]modeltest.UserInfo{
+		{UID: 1, FirstName: "ignored", Sex: "female"},
+	}, "sex")
+	a.NotError(err).Equal(count, 1)
+
+	// 不能更新主键
+	_, err = tx.UpdateMany([]*modeltest.UserInfo{&modeltest.UserInfo{UID: 1}}, "uid")
+	a.Error(err)
+	a.NotError(tx.Commit())
+
+	u1 := &modeltest.UserInfo{UID: 1}
+	a.NotError(db.Select(u1))
+	a.Equal(u1, &modeltest.UserInfo{UID: 1, FirstName: "uf1", LastName: "ul1", Sex: "female"})
+
+	u2 := &modeltest.UserInfo{UID: 2}
+	a.NotError(db.Select(u2))
+	a.Equal(u2, &modeltest.UserInfo{UID: 2, FirstName: "uf2", LastName: "ul2", Sex: ""})
+
+	count, err = db.DeleteMany([]*modeltest.UserInfo{
+		&modeltest.UserInfo{UID: 1},
+		&modeltest.UserInfo{UID: 2},
+		&modeltest.UserInfo{UID: 3}, // 不存在
+	})
+	a.NotError(err).Equal(count, 2)
+	hasCount(db, a, "user_info", 0)
+
+	// 空数组
+	count, err = db.DeleteMany([]*modeltest.UserInfo{})
+	a.NotError(err).Equal(count, 0)
+
+	// 非数组
+	_, err = db.DeleteMany(&modeltest.UserInfo{UID: 1})
+	a.Error(err)
+}
+
+// 联合主键
+type orderItem struct {
+	OrderID int64 `orm:"name(order_id);pk"`
+	Line    int64 `orm:"name(line);pk"`
+	Qty     int64 `orm:"name(qty)"`
+}
+
+func (m *orderItem) Meta() string {
+	return "name(order_items)"
+}
+
+func TestTx_UpdateMany_DeleteMany_compositePK(t *testing.T) {
+	a := assert.New(t)
+
+	db := newDB(a)
+	defer func() {
+		a.NotError(db.Drop(&orderItem{}))
+		a.NotError(db.Close())
+		closeDB(a)
+	}()
+	a.NotError(db.Create(&orderItem{}))
+
+	a.NotError(db.InsertMany([]*orderItem{
+		{OrderID: 1, Line: 1, Qty: 1},
+		{OrderID: 1, Line: 2, Qty: 2},
+		{OrderID: 2, Line: 1, Qty: 3},
+	}))
+
+	tx, err := db.Begin()
+	a.NotError(err)
+	count, err := tx.UpdateMany([]*orderItem{
+		{OrderID: 1, Line: 2, Qty: 20},
+		{OrderID: 2, Line: 1, Qty: 30},
+	})
+	a.NotError(err).Equal(count, 2)
+	a.NotError(tx.Commit())
+
+	item := &orderItem{OrderID: 1, Line: 1}
+	a.NotError(db.Select(item))
+	a.Equal(item.Qty, 1)
+	item = &orderItem{OrderID: 1, Line: 2}
+	a.NotError(db.Select(item))
+	a.Equal(item.Qty, 20)
+	item = &orderItem{OrderID: 2, Line: 1}
+	a.NotError(db.Select(item))
+	a.Equal(item.Qty, 30)
+
+	count, err = db.DeleteMany([]*orderItem{
+		{OrderID: 1, Line: 2},
+		{OrderID: 2, Line: 1},
+		{OrderID: 2, Line: 2}, // 不存在
+	})
+	a.NotError(err).Equal(count, 2)
+	hasCount(db, a, "order_items", 1)
+}
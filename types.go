@@ -107,6 +107,61 @@ type Dialect interface {
 	//
 	// 创建表可能生成多条语句，比如创建表，以及相关的创建索引语句。
 	CreateTableSQL(m *Model) ([]string, error)
+
+	// 生成为已经存在的表 m 添加外键 fkName 的语句。
+	//
+	// 外键存在循环引用时，MultCreate() 等会先创建不包含这些外键的表，
+	// 再通过此语句添加外键。返回空字符串表示数据库允许外键引用尚未创建的表，
+	// 此时外键直接包含在创建表的语句中。
+	AddForeignKeySQL(m *Model, fkName string) (string, error)
+
+	// 生成删除表 m 中外键 fkName 的语句，与 AddForeignKeySQL() 相对应。
+	//
+	// MultDrop() 会在删除存在循环引用的表之前执行此语句，
+	// 返回空字符串表示不需要删除外键。
+	DropForeignKeySQL(m *Model, fkName string) (string, error)
+
+	// 返回模型 m 中需要在整个数据库中保持唯一的约束名称。
+	//
+	// 比如 postgres 中索引和唯一约束的名称在整个 schema 中都不能重复，
+	// 而 mysql 中只有外键等约束才有此要求。Validate() 会据此检测各表之间的约束名是否冲突。
+	GlobalConstraintNames(m *Model) []string
+
+	// 生成同时更新多条记录的语句。
+	//
+	// keys 为用于定位记录的列，一般为主键；cols 为需要更新的列；
+	// rows 中的每一个元素表示一条记录，依次为 keys 和 cols 中各列对应的值。
+	// 返回的语句中每条记录所使用的参数数量不能超过 (len(keys)+1)*len(cols)+len(keys)。
+	UpdateManySQL(m *Model, keys, cols []*Column, rows [][]interface{}) (string, []interface{}, error)
+
+	// 是否支持 (col1,col2) IN((?,?),(?,?)) 形式的多列比较。
+	//
+	// 不支持时，DeleteMany() 等会使用 ((col1=? AND col2=?) OR ...) 的形式代替，
+	// 比如 sqlite3 只允许在子查询中使用此类比较。
+	RowValues() bool
+
+	// 字符串中的 \ 是否为转义字符。
+	//
+	// 转换命名参数时会根据此值判断引号的结束位置，
+	// 比如 mysql 中 'it\'s' 是一个完整的字符串，而 postgres 和 sqlite3 中 'C:\' 是一个完整的字符串。
+	BackslashEscapes() bool
+
+	// 单条语句中允许使用的最大参数数量。
+	//
+	// 批量插入等操作会根据此值将数据拆分成多条语句执行。
+	// 返回值小于等于 0 表示没有限制。
+	MaxArgs() int
+
+	// 判断 err 是否为可以通过重试解决的临时性错误。
+	//
+	// 比如死锁、序列化失败以及数据库被锁定等，
+	// DB.TransactionRetry() 会在遇到此类错误时重新执行整个事务。
+	Retryable(err error) bool
+
+	// 将数据库驱动返回的错误转换成 orm 中预定义的错误类型。
+	//
+	// 违反约束的错误应该转换成 *ConstraintError，无法识别的错误原样返回。
+	TranslateError(err error) error
 }
 
 // SQL 用于生成 SQL 语句
@@ -125,8 +180,15 @@ func (sql *SQL) Update() *sqlbuilder.UpdateStmt {
 }
 
 // Insert 生成插入语句
+//
+// InsertStmt.LastInsertID() 可能会通过 QueryRow() 执行 RETURNING 等语句，
+// 所以在指定了从数据库的 DB 中，插入语句总是在主数据库中执行。
 func (sql *SQL) Insert() *sqlbuilder.InsertStmt {
-	return sqlbuilder.Insert(sql.engine, sql.engine.Dialect())
+	e := sql.engine
+	if db, ok := e.(*DB); ok {
+		e = db.Primary()
+	}
+	return sqlbuilder.Insert(e, e.Dialect())
 }
 
 // Select 生成插入语句
@@ -0,0 +1,113 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate 检测模型在数据库 d 中是否存在问题。
+//
+// 一般在程序启动时调用，以便尽早发现只有在执行 DDL 时才会暴露的问题：
+//  - 模型本身的 struct tag 错误；
+//  - 字段类型无法转换成 d 中的类型，比如未指定长度的浮点数等；
+//  - 外键引用的表或列不在 objs 之中；
+//  - 需要全局唯一的约束名在不同的表之间存在冲突，具体可参考 Dialect.GlobalConstraintNames()。
+//
+// 所有的问题会一次性返回，返回值的类型为 *ValidationError。
+func Validate(d Dialect, objs ...interface{}) error {
+	errs := make([]error, 0, 10)
+	ms := make([]*Model, 0, len(objs))
+
+	for _, obj := range objs {
+		m, err := NewModel(obj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%T: %v", obj, err))
+			continue
+		}
+		ms = append(ms, m)
+	}
+
+	names := make(map[string]*Model, len(ms))
+	for _, m := range ms {
+		names[m.Name] = m
+	}
+
+	constraints := make(map[string]*Model, len(ms))
+	for _, m := range ms {
+		errs = append(errs, validateTypes(d, m)...)
+		errs = append(errs, validateFKs(m, names)...)
+
+		global := d.GlobalConstraintNames(m)
+		sort.Strings(global)
+		for _, name := range global {
+			key := strings.ToLower(name)
+			if prev, found := constraints[key]; found && prev != m {
+				errs = append(errs, fmt.Errorf("%s: 约束名 %s 与表 %s 中的约束名冲突", m.Name, name, prev.Name))
+				continue
+			}
+			constraints[key] = m
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// 通过 d.CreateTableSQL() 检测各列的类型能否被 d 所接受。
+//
+// 为了能同时返回多个列的错误，每一列都单独生成一张只包含该列的表。
+func validateTypes(d Dialect, m *Model) []error {
+	var errs []error
+
+	cols := make([]string, 0, len(m.Cols))
+	for name := range m.Cols {
+		cols = append(cols, name)
+	}
+	sort.Strings(cols)
+
+	for _, name := range cols {
+		tbl := newModel(m.Name)
+		tbl.Cols[name] = m.Cols[name]
+		if _, err := d.CreateTableSQL(tbl); err != nil {
+			errs = append(errs, fmt.Errorf("%s: 列 %s 无法转换成数据库类型: %v", m.Name, name, err))
+		}
+	}
+
+	if len(errs) > 0 { // 列存在错误时，整表的错误大概率是重复的
+		return errs
+	}
+
+	if _, err := d.CreateTableSQL(m); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %v", m.Name, err))
+	}
+	return errs
+}
+
+// 检测 m 中的外键引用的表和列是否存在于 models 中
+func validateFKs(m *Model, models map[string]*Model) []error {
+	var errs []error
+
+	for _, name := range fkNames(m) {
+		fk := m.FK[name]
+		tbl := refTableName(fk.RefTableName)
+
+		ref, found := models[tbl]
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: 外键 %s 引用的表 %s 不存在", m.Name, name, tbl))
+			continue
+		}
+
+		if _, found := ref.Cols[fk.RefColName]; !found {
+			errs = append(errs, fmt.Errorf("%s: 外键 %s 引用的列 %s.%s 不存在", m.Name, name, tbl, fk.RefColName))
+		}
+	}
+
+	return errs
+}
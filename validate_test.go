@@ -0,0 +1,97 @@
+// Copyright 2018 by caixw, All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package orm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/issue9/assert"
+	"github.com/issue9/orm"
+	"github.com/issue9/orm/dialect"
+	"github.com/issue9/orm/internal/modeltest"
+)
+
+// 各列的类型都存在问题
+type invalidTypes struct {
+	ID    int64            `orm:"name(id);ai"`
+	Price float64          `orm:"name(price)"`
+	Name  string           `orm:"name(name)"`
+	Attrs map[string]int64 `orm:"name(attrs)"`
+}
+
+func (m *invalidTypes) Meta() string {
+	return "name(invalid_types)"
+}
+
+// 外键引用了不存在的表和列
+type invalidFK struct {
+	ID    int64 `orm:"name(id);ai"`
+	Group int64 `orm:"name(group);fk(fk_group,#groups,gid)"`
+	Tag   int64 `orm:"name(tag);fk(fk_tag,#tags,id)"`
+}
+
+func (m *invalidFK) Meta() string {
+	return "name(invalid_fk)"
+}
+
+// 与 modeltest.User 使用了相同的索引名
+type sameIndex struct {
+	ID   int64  `orm:"name(id);ai"`
+	Name string `orm:"name(name);len(20);index(index_name)"`
+}
+
+func (m *sameIndex) Meta() string {
+	return "name(same_index)"
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NotError(orm.Validate(dialect.Mysql(), &modeltest.Group{}, &modeltest.User{}))
+	a.NotError(orm.Validate(dialect.Postgres(), &modeltest.Group{}, &modeltest.Admin{}, &modeltest.UserInfo{}))
+
+	// 类型错误，sqlite3 不区分长度，仅 map 无法转换
+	err := orm.Validate(dialect.Sqlite3(), &invalidTypes{})
+	verr, ok := err.(*orm.ValidationError)
+	a.True(ok).Equal(1, len(verr.Errors))
+	a.Contains(err.Error(), "attrs")
+
+	err = orm.Validate(dialect.Mysql(), &invalidTypes{})
+	verr, ok = err.(*orm.ValidationError)
+	a.True(ok).Equal(3, len(verr.Errors))
+	a.Contains(verr.Errors[0].Error(), "attrs").
+		Contains(verr.Errors[1].Error(), "name").
+		Contains(verr.Errors[2].Error(), "price")
+
+	// 外键
+	err = orm.Validate(dialect.Sqlite3(), &invalidFK{}, &modeltest.Group{})
+	verr, ok = err.(*orm.ValidationError)
+	a.True(ok).Equal(2, len(verr.Errors))
+	a.Contains(verr.Errors[0].Error(), "groups.gid").
+		Contains(verr.Errors[1].Error(), "tags")
+
+	// 约束名冲突，仅 postgres 和 sqlite3 中的索引名需要全局唯一。
+	a.NotError(orm.Validate(dialect.Mysql(), &modeltest.User{}, &sameIndex{}))
+	err = orm.Validate(dialect.Postgres(), &modeltest.User{}, &sameIndex{})
+	verr, ok = err.(*orm.ValidationError)
+	a.True(ok).Equal(1, len(verr.Errors))
+	a.Contains(err.Error(), "index_name")
+
+	// mysql 中 check 的约束名需要全局唯一
+	err = orm.Validate(dialect.Mysql(), &modeltest.User{}, &modeltest.UserInfo{})
+	a.Error(err).Contains(err.Error(), "chk_name")
+
+	// 模型本身的错误
+	err = orm.Validate(dialect.Sqlite3(), 5, &modeltest.Group{})
+	verr, ok = err.(*orm.ValidationError)
+	a.True(ok).Equal(1, len(verr.Errors))
+
+	// 同时返回所有的错误
+	err = orm.Validate(dialect.Postgres(), &invalidTypes{}, &invalidFK{}, &modeltest.User{}, &sameIndex{})
+	verr, ok = err.(*orm.ValidationError)
+	a.True(ok).Equal(6, len(verr.Errors))
+	a.True(errors.As(err, &verr))
+}